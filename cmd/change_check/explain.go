@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"industry_backend_go/internal/changepolicy"
+	"industry_backend_go/internal/config"
+	"os"
+	"strings"
+)
+
+// runExplain — подкоманда `change_check explain <path>...`: показывает, какие
+// правила совпали с путём или почти совпали, и итоговое решение.
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	cfgPath := fs.String("config", "./.etc/config.json", "config file")
+	status := fs.String("status", "M", "git status to evaluate paths with (A/M/D/R/C/T)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: change_check explain [-config FILE] [-status S] <path>...")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+	policy, err := changepolicy.New(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config read error:", err)
+		return 2
+	}
+
+	st := strings.ToUpper(strings.TrimSpace(*status))
+	for i, raw := range fs.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		printExplanation(policy.Explain(raw, st))
+	}
+	return 0
+}
+
+func printExplanation(e changepolicy.Explanation) {
+	fmt.Printf("path:       %q\n", e.Path)
+	if e.Normalized != "" {
+		fmt.Printf("hint:       checked as is; did you mean %q?\n", e.Normalized)
+	}
+	fmt.Printf("status:     %s\n", e.Status)
+	if e.Path == "" {
+		fmt.Println("result:     allowed (empty path)")
+		return
+	}
+
+	for _, m := range e.Matches {
+		fmt.Printf("  %-40s %s\n", m.Verdict, m.Rule)
+	}
+	if e.Skipped > 0 {
+		fmt.Printf("  (%d other rules do not match)\n", e.Skipped)
+	}
+
+	if e.Decision.Allowed {
+		fmt.Printf("result:     allowed by %s\n", e.Decision.Rule)
+	} else {
+		fmt.Printf("result:     denied: %s\n", e.Decision.Rule)
+	}
+}
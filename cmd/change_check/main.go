@@ -1,351 +1,264 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"industry_backend_go/internal/changepolicy"
 	"industry_backend_go/internal/config"
 	"os"
-	"path"
-	"regexp"
-	"sort"
-	"strings"
 	"time"
 )
 
-type Change struct {
-	Status string `json:"status"`
-	Path   string `json:"path,omitempty"`
-	From   string `json:"from,omitempty"`
-	To     string `json:"to,omitempty"`
-	Raw    string `json:"raw"`
+func main() {
+	os.Exit(run())
 }
 
-type Report struct {
-	OK             bool     `json:"ok"`
-	CheckedAt      string   `json:"checked_at"`
-	DiffFile       string   `json:"diff_file"`
-	ConfigFile     string   `json:"config_file"`
-	AllowList      []string `json:"allow_list"`
-	ChangedPaths   []string `json:"changed_paths"`
-	Unexpected     []string `json:"unexpected"`
-	UnexpectedBySt []Change `json:"unexpected_by_status,omitempty"`
-}
+func run() int {
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		return runExplain(os.Args[2:])
+	}
 
-func main() {
 	cfgPath := flag.String("config", "./.etc/config.json", "config file")
-	diffPath := flag.String("diff", "changed_files.raw", "path to diff file (prefer changed_files.raw)")
-	outPath := flag.String("out", "change-policy-result.json", "output json file")
+	diffPath := flag.String("diff", "changed_files.raw", "path to git diff --name-status or --raw output (line-based or -z)")
+	outPath := flag.String("out", "change-policy-result.json", "output file (with several formats the extension is replaced per format)")
+	formatList := flag.String("format", "json", "output formats, comma separated: json, sarif, junit")
+	baselineDir := flag.String("baseline", "", "baseline tree directory (with -current: compare trees instead of reading -diff)")
+	currentDir := flag.String("current", "", "current tree directory (with -baseline)")
+	mirrorDir := flag.String("mirror", "", "local git mirror of diff.original.repo: baseline is taken from it by ref (current defaults to the HEAD commit of .)")
+	refName := flag.String("ref", "", "baseline ref for -mirror (default: diff.original.ref)")
+	numstatPath := flag.String("numstat", "", "optional git diff --numstat output for -diff mode (line budgets)")
+	rootDir := flag.String("root", "", "current tree root for content checks of changed files (default: -current or .)")
+	summaryPath := flag.String("summary-md", "", "append a markdown summary to this file (e.g. $GITHUB_STEP_SUMMARY)")
+	revertPatch := flag.String("revert-patch", "", "write a patch (for git apply) reverting disallowed changes; needs -baseline/-current or -mirror")
+	revertScript := flag.String("revert-script", "", "write a shell script of git checkout/git rm reverting disallowed changes")
 	flag.Parse()
 
-	cfg, err := config.Load(*cfgPath)
+	formats, err := changepolicy.Formats.Parse(*formatList)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "ERROR:", err)
-		os.Exit(2)
-	}
-	matchers, err := compileAllowList(cfg.Diff.AllowList)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "config read error:", err)
-		os.Exit(2)
+		return 2
 	}
 
-	changes, err := readChanges(*diffPath)
+	cfg, err := config.Load(*cfgPath)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "diff read error:", err)
-		os.Exit(2)
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
 	}
-
-	changedSet := map[string]struct{}{}
-	var unexpected []string
-	unexpectedSet := map[string]struct{}{}
-	var unexpectedBySt []Change
-
-	addChanged := func(p string) {
-		p = normalizePath(p)
-		if p == "" {
-			return
-		}
-		changedSet[p] = struct{}{}
-		if !isAllowed(p, matchers) {
-			if _, ok := unexpectedSet[p]; !ok {
-				unexpectedSet[p] = struct{}{}
-				unexpected = append(unexpected, p)
-			}
-		}
+	policy, err := changepolicy.New(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config read error:", err)
+		return 2
 	}
 
-	for _, ch := range changes {
-		if ch.Path != "" {
-			addChanged(ch.Path)
+	var baselineRef, baselineCommit, currentLabel string
+	if *mirrorDir != "" {
+		if *baselineDir != "" {
+			fmt.Fprintln(os.Stderr, "ERROR: -mirror and -baseline are mutually exclusive")
+			return 2
 		}
-		if ch.From != "" {
-			addChanged(ch.From)
+		baselineRef = *refName
+		if baselineRef == "" {
+			baselineRef = cfg.Diff.Original.Ref
 		}
-		if ch.To != "" {
-			addChanged(ch.To)
+		if baselineRef == "" {
+			fmt.Fprintln(os.Stderr, "ERROR: no baseline ref (set diff.original.ref or -ref)")
+			return 2
 		}
-	}
 
-	changedPaths := make([]string, 0, len(changedSet))
-	for p := range changedSet {
-		changedPaths = append(changedPaths, p)
-	}
-	sort.Strings(changedPaths)
-	sort.Strings(unexpected)
-
-	// детализируем unexpectedBySt (чтобы было понятно, что именно случилось)
-	for _, ch := range changes {
-		paths := []string{}
-		if ch.Path != "" {
-			paths = append(paths, normalizePath(ch.Path))
+		gitDir, err := changepolicy.ResolveMirror(*mirrorDir, cfg.Diff.Original.Repo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mirror error:", err)
+			return 2
 		}
-		if ch.From != "" {
-			paths = append(paths, normalizePath(ch.From))
+		baselineCommit, err = changepolicy.ResolveRef(gitDir, baselineRef)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mirror error:", err)
+			return 2
 		}
-		if ch.To != "" {
-			paths = append(paths, normalizePath(ch.To))
+		dir, err := changepolicy.CheckoutRef(gitDir, baselineCommit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mirror error:", err)
+			return 2
 		}
-
-		bad := false
-		for _, p := range paths {
-			if p == "" {
-				continue
+		defer os.RemoveAll(dir)
+
+		*baselineDir = dir
+		if *currentDir == "" {
+			// рабочее дерево не годится: в нём неотслеживаемые и игнорируемые
+			// файлы (.idea/, отчёты), поэтому сравниваем с закоммиченным HEAD
+			dir, commit, err := checkoutHead(".")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "current tree error:", err)
+				return 2
 			}
-			if !isAllowed(p, matchers) {
-				bad = true
-				break
-			}
-		}
-		if bad {
-			unexpectedBySt = append(unexpectedBySt, ch)
+			defer os.RemoveAll(dir)
+			*currentDir = dir
+			currentLabel = "HEAD " + commit
 		}
 	}
 
-	ok := len(unexpected) == 0
+	if (*baselineDir == "") != (*currentDir == "") {
+		fmt.Fprintln(os.Stderr, "ERROR: -baseline and -current must be used together")
+		return 2
+	}
+	if *revertPatch != "" && *baselineDir == "" {
+		fmt.Fprintln(os.Stderr, "ERROR: -revert-patch needs baseline and current trees (-baseline/-current or -mirror)")
+		return 2
+	}
 
-	rep := Report{
-		OK:             ok,
-		CheckedAt:      time.Now().UTC().Format(time.RFC3339),
-		DiffFile:       *diffPath,
-		ConfigFile:     *cfgPath,
-		AllowList:      cfg.Diff.AllowList,
-		ChangedPaths:   changedPaths,
-		Unexpected:     unexpected,
-		UnexpectedBySt: unexpectedBySt,
+	var changes []changepolicy.Change
+	if *baselineDir != "" {
+		changes, err = changepolicy.CompareDirs(*baselineDir, *currentDir)
+	} else {
+		changes, err = changepolicy.ReadChanges(*diffPath)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff read error:", err)
+		return 2
 	}
 
-	if *outPath != "" {
-		if err := writeJSON(*outPath, rep); err != nil {
-			fmt.Fprintln(os.Stderr, "report write error:", err)
-			os.Exit(2)
+	if *rootDir == "" {
+		*rootDir = "."
+		if *currentDir != "" {
+			*rootDir = *currentDir
 		}
 	}
 
-	if ok {
-		fmt.Printf("OK: all changes are allowed. Changed files: %d\n", len(changedPaths))
-		os.Exit(0)
+	if *baselineDir == "" {
+		changepolicy.FillSymlinkTargets(*rootDir, changes)
 	}
 
-	fmt.Printf("FAIL: unexpected changes detected: %d\n", len(unexpected))
-	for _, p := range unexpected {
-		fmt.Println(p)
+	rep := policy.Evaluate(changes)
+	rep.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+	if *baselineDir != "" {
+		rep.Baseline = *baselineDir
+		rep.Current = *currentDir
+		if currentLabel != "" {
+			rep.Current = currentLabel
+		}
+		if *mirrorDir != "" {
+			rep.Baseline = *mirrorDir
+			rep.BaselineRef = baselineRef
+			rep.BaselineCommit = baselineCommit
+		}
+	} else {
+		rep.DiffFile = *diffPath
 	}
-	os.Exit(1)
-}
+	rep.ConfigFile = *cfgPath
 
-func writeJSON(p string, v any) error {
-	b, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(pathDir(p), 0o755); err != nil {
-		return err
+	if err := policy.CheckImports(*rootDir, &rep); err != nil {
+		fmt.Fprintln(os.Stderr, "import check error:", err)
+		return 2
 	}
-	return os.WriteFile(p, b, 0o644)
-}
 
-func pathDir(p string) string {
-	// маленький helper, чтобы не тянуть filepath (нам нужны / пути в репо)
-	i := strings.LastIndex(p, "/")
-	if i < 0 {
-		return "."
+	var stats []changepolicy.FileStat
+	switch {
+	case *baselineDir != "":
+		stats, err = changepolicy.DiffStats(*baselineDir, *currentDir, changes)
+	case *numstatPath != "":
+		stats, err = changepolicy.ReadNumstat(*numstatPath)
+	case policy.HasBudgets():
+		fmt.Fprintln(os.Stderr, "WARN: diff budgets are configured, but no -numstat given; budgets are not checked")
 	}
-	if i == 0 {
-		return "/"
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff stats error:", err)
+		return 2
 	}
-	return p[:i]
-}
-
-type matcher struct {
-	pattern string
-	re      *regexp.Regexp
-}
+	policy.CheckBudgets(&rep, stats)
 
-func compileAllowList(patterns []string) ([]matcher, error) {
-	out := make([]matcher, 0, len(patterns))
-	for _, pat := range patterns {
-		pat = strings.TrimSpace(pat)
-		if pat == "" {
-			continue
-		}
-		re, err := globToRegex(pat)
-		if err != nil {
-			return nil, fmt.Errorf("pattern %q: %w", pat, err)
+	if *outPath != "" {
+		for _, f := range formats {
+			if err := changepolicy.WriteReport(changepolicy.Formats.OutputPath(*outPath, f, len(formats) > 1), f, rep); err != nil {
+				fmt.Fprintln(os.Stderr, "report write error:", err)
+				return 2
+			}
 		}
-		out = append(out, matcher{pattern: pat, re: re})
 	}
-	return out, nil
-}
 
-func isAllowed(p string, matchers []matcher) bool {
-	if p == "" {
-		return true
+	// в подсказках и скрипте ref baseline — как его видит студент в своём клоне
+	hintRef := baselineRef
+	if hintRef == "" {
+		hintRef = "upstream/" + cfg.Diff.Original.Ref
 	}
-	for _, m := range matchers {
-		if m.re.MatchString(p) {
-			return true
+	if *summaryPath != "" {
+		if err := writeSummary(*summaryPath, rep, hintRef); err != nil {
+			fmt.Fprintln(os.Stderr, "summary write error:", err)
+			return 2
 		}
 	}
-	return false
-}
-
-func globToRegex(pat string) (*regexp.Regexp, error) {
-	pat = normalizePath(pat)
-	if strings.HasSuffix(pat, "/") {
-		// "dir/" => всё внутри
-		pat = pat + "**"
+	if *revertScript != "" {
+		if err := os.WriteFile(*revertScript, changepolicy.RevertScript(rep, hintRef), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, "revert script write error:", err)
+			return 2
+		}
 	}
-
-	var b strings.Builder
-	b.WriteString("^")
-	for i := 0; i < len(pat); i++ {
-		ch := pat[i]
-
-		if ch == '*' {
-			// ** => match across slashes
-			if i+1 < len(pat) && pat[i+1] == '*' {
-				b.WriteString(".*")
-				i++
-				continue
-			}
-			// * => match within a path segment
-			b.WriteString(`[^/]*`)
-			continue
+	if *revertPatch != "" {
+		patch, skipped, err := changepolicy.RevertPatch(*baselineDir, *currentDir, rep)
+		if err == nil {
+			err = os.WriteFile(*revertPatch, patch, 0o644)
 		}
-
-		if ch == '?' {
-			b.WriteString(`[^/]`)
-			continue
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "revert patch write error:", err)
+			return 2
 		}
-
-		// escape regexp metachars
-		if strings.ContainsRune(`.+()|[]{}^$\/`, rune(ch)) {
-			b.WriteByte('\\')
+		for _, p := range skipped {
+			fmt.Fprintf(os.Stderr, "WARN: %s is binary or a submodule and is not in the revert patch; use -revert-script\n", p)
 		}
-		b.WriteByte(ch)
 	}
-	b.WriteString("$")
 
-	return regexp.Compile(b.String())
-}
-
-func normalizePath(p string) string {
-	p = strings.TrimSpace(p)
-	if p == "" {
-		return ""
+	if rep.OK {
+		fmt.Printf("OK: all changes are allowed. Changed files: %d\n", len(rep.ChangedPaths))
+		return 0
 	}
-	p = strings.ReplaceAll(p, "\\", "/")
 
-	// часто из git diff вылезают префиксы a/ b/
-	for {
-		if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
-			p = p[2:]
-			continue
+	if len(rep.Unexpected) > 0 {
+		fmt.Printf("FAIL: unexpected changes detected: %d\n", len(rep.Unexpected))
+		for _, d := range rep.Decisions {
+			if !d.Allowed {
+				fmt.Printf("%s\t%s\t%s\n", d.Status, d.Path, d.Rule)
+			}
 		}
-		break
 	}
-
-	// если diff делали между baseline/current, может прилипнуть префикс
-	for _, pref := range []string{"./", "baseline/", "current/", "../baseline/"} {
-		if strings.HasPrefix(p, pref) {
-			p = strings.TrimPrefix(p, pref)
+	if len(rep.ImportViolations) > 0 {
+		fmt.Printf("FAIL: forbidden imports detected: %d\n", len(rep.ImportViolations))
+		for _, v := range rep.ImportViolations {
+			fmt.Printf("%s:%d\t%s\n", v.File, v.Line, v.Reason)
 		}
 	}
-
-	p = strings.TrimPrefix(p, "/")
-	p = path.Clean(p)
-	if p == "." {
-		return ""
-	}
-	return p
-}
-
-func readChanges(diffFile string) ([]Change, error) {
-	f, err := os.Open(diffFile)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var out []Change
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		raw := sc.Text()
-		line := strings.TrimSpace(raw)
-		if line == "" {
-			continue
-		}
-
-		ch, ok := parseDiffLine(raw)
-		if !ok {
-			// если формат непонятен — считаем как "изменённый файл = вся строка"
-			p := normalizePath(line)
-			if p != "" {
-				out = append(out, Change{Status: "?", Path: p, Raw: raw})
+	if n := rep.BudgetViolations(); n > 0 {
+		fmt.Printf("FAIL: diff budgets exceeded: %d\n", n)
+		for _, st := range rep.Files {
+			for _, e := range st.Exceeded {
+				fmt.Printf("%s\t+%d -%d\t%s\n", st.Path, st.Added, st.Removed, e)
 			}
-			continue
 		}
-		out = append(out, ch)
-	}
-	if err := sc.Err(); err != nil {
-		return nil, err
 	}
-	return out, nil
+	return 1
 }
 
-func parseDiffLine(raw string) (Change, bool) {
-	line := strings.TrimRight(raw, "\r\n")
-	var parts []string
-
-	// предпочтительно таб-разделение (как в changed_files.raw)
-	if strings.Contains(line, "\t") {
-		parts = strings.Split(line, "\t")
-	} else {
-		parts = strings.Fields(line)
+// writeSummary дописывает markdown в конец файла: $GITHUB_STEP_SUMMARY общий для всех шагов job.
+func writeSummary(path string, rep changepolicy.Report, baselineRef string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
 	}
-
-	if len(parts) < 2 {
-		return Change{}, false
+	if err := changepolicy.RenderMarkdown(f, rep, baselineRef); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
+}
 
-	st := parts[0]
-	ch := Change{Status: st, Raw: raw}
-
-	lead := st
-	if len(lead) > 0 {
-		lead = lead[:1]
+// checkoutHead выгружает HEAD репозитория dir во временный каталог.
+func checkoutHead(dir string) (tree, commit string, err error) {
+	gitDir, err := changepolicy.ResolveMirror(dir, "")
+	if err != nil {
+		return "", "", err
 	}
-
-	if lead == "R" || lead == "C" {
-		if len(parts) < 3 {
-			return Change{}, false
-		}
-		ch.From = parts[1]
-		ch.To = parts[2]
-		return ch, true
+	commit, err = changepolicy.ResolveRef(gitDir, "HEAD")
+	if err != nil {
+		return "", "", err
 	}
-
-	ch.Path = parts[1]
-	return ch, true
+	tree, err = changepolicy.CheckoutRef(gitDir, commit)
+	return tree, commit, err
 }
@@ -104,6 +104,18 @@ func mapStatus(status, unknownMsg string) (message, color string) {
 		return "ok", "brightgreen"
 	case "fail":
 		return "fail", "red"
+	case "flaky":
+		return "flaky", "orange"
+	case "build_failed":
+		return "build failed", "red"
+	case "panic":
+		return "panic", "red"
+	case "timeout":
+		return "timeout", "red"
+	case "data_race":
+		return "data race", "red"
+	case "slow":
+		return "slow", "yellow"
 	default:
 		return unknownMsg, "lightgrey"
 	}
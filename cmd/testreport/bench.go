@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"industry_backend_go/internal/config"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type BenchmarkResult struct {
+	Name        string   `json:"name"`
+	Runs        int      `json:"runs"` // результатов (-count)
+	NsPerOp     float64  `json:"ns_per_op"`
+	BytesPerOp  *float64 `json:"bytes_per_op,omitempty"` // только с -benchmem
+	AllocsPerOp *float64 `json:"allocs_per_op,omitempty"`
+	Violations  []string `json:"violations,omitempty"`
+}
+
+// benchLineRe — строка результата: "BenchmarkX-8  \t 1000\t 12.5 ns/op\t 16 B/op\t 1 allocs/op".
+var benchLineRe = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+(.+)$`)
+
+// benchCollector собирает результаты бенчмарков из вывода go test -json.
+// Строка результата может прийти несколькими событиями, а повторы -count —
+// без поля Test, поэтому вывод склеивается по пакету целиком.
+type benchCollector struct {
+	suffix  string                           // "-8" при GOMAXPROCS=8; при 1 go test суффикс не пишет
+	partial map[string]string                // package → незавершённая строка
+	samples map[string]map[string][]benchRun // package → benchmark → прогоны
+	order   map[string][]string
+}
+
+type benchRun struct {
+	metrics map[string]float64 // единица → значение
+}
+
+// newBenchCollector: procs — GOMAXPROCS прогона бенчмарков. Снимается только
+// суффикс -procs: "-100" в BenchmarkGet/size-100 — часть имени подтеста.
+func newBenchCollector(procs int) *benchCollector {
+	suffix := ""
+	if procs > 1 {
+		suffix = "-" + strconv.Itoa(procs)
+	}
+	return &benchCollector{
+		suffix:  suffix,
+		partial: map[string]string{},
+		samples: map[string]map[string][]benchRun{},
+		order:   map[string][]string{},
+	}
+}
+
+func (b *benchCollector) add(ev TestEvent) {
+	if ev.Action != "output" || ev.Package == "" {
+		return
+	}
+	buf := b.partial[ev.Package] + ev.Output
+	for {
+		line, rest, ok := strings.Cut(buf, "\n")
+		if !ok {
+			break
+		}
+		b.parseLine(ev.Package, line)
+		buf = rest
+	}
+	b.partial[ev.Package] = buf
+}
+
+func (b *benchCollector) parseLine(pkg, line string) {
+	m := benchLineRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return
+	}
+	fields := strings.Fields(m[3])
+	if len(fields)%2 != 0 {
+		return
+	}
+	run := benchRun{metrics: map[string]float64{}}
+	for i := 0; i < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return
+		}
+		run.metrics[fields[i+1]] = v
+	}
+	if _, ok := run.metrics["ns/op"]; !ok {
+		return
+	}
+
+	name := m[1]
+	if b.suffix != "" {
+		name = strings.TrimSuffix(name, b.suffix)
+	}
+	byName := b.samples[pkg]
+	if byName == nil {
+		byName = map[string][]benchRun{}
+		b.samples[pkg] = byName
+	}
+	if _, seen := byName[name]; !seen {
+		b.order[pkg] = append(b.order[pkg], name)
+	}
+	byName[name] = append(byName[name], run)
+}
+
+// results — медианы по прогонам каждого бенчмарка пакета.
+func (b *benchCollector) results(pkg string) []*BenchmarkResult {
+	var out []*BenchmarkResult
+	for _, name := range b.order[pkg] {
+		runs := b.samples[pkg][name]
+		res := &BenchmarkResult{Name: name, Runs: len(runs), NsPerOp: medianMetric(runs, "ns/op")}
+		if hasMetric(runs, "B/op") {
+			v := medianMetric(runs, "B/op")
+			res.BytesPerOp = &v
+		}
+		if hasMetric(runs, "allocs/op") {
+			v := medianMetric(runs, "allocs/op")
+			res.AllocsPerOp = &v
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func hasMetric(runs []benchRun, unit string) bool {
+	for _, r := range runs {
+		if _, ok := r.metrics[unit]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func medianMetric(runs []benchRun, unit string) float64 {
+	var v []float64
+	for _, r := range runs {
+		if x, ok := r.metrics[unit]; ok {
+			v = append(v, x)
+		}
+	}
+	return median(v)
+}
+
+type benchLimit struct {
+	cfg config.BenchmarkLimit
+	re  *regexp.Regexp
+}
+
+func compileBenchLimits(limits []config.BenchmarkLimit) ([]benchLimit, error) {
+	out := make([]benchLimit, 0, len(limits))
+	for i, l := range limits {
+		if _, err := path.Match(l.Package, ""); err != nil {
+			return nil, fmt.Errorf("tests.benchmarks[%d]: bad package pattern %q: %w", i, l.Package, err)
+		}
+		re, err := regexp.Compile(l.Name)
+		if err != nil {
+			return nil, fmt.Errorf("tests.benchmarks[%d]: %w", i, err)
+		}
+		if (l.RelativeTo == "") != (l.MaxRatio == 0) {
+			return nil, fmt.Errorf("tests.benchmarks[%d]: relative_to and max_ratio must be set together", i)
+		}
+		out = append(out, benchLimit{cfg: l, re: re})
+	}
+	return out, nil
+}
+
+// applyBenchmarks добавляет бенчмарки в отчёт и проверяет пороги. Пакет,
+// прошедший тесты, но нарушивший порог, получает статус slow.
+func applyBenchmarks(bc *benchCollector, limits []benchLimit, results map[string]*PackageResult) {
+	for pkg, res := range results {
+		benches := bc.results(pkg)
+		if len(benches) == 0 {
+			continue
+		}
+		byName := map[string]*BenchmarkResult{}
+		for _, b := range benches {
+			byName[b.Name] = b
+		}
+
+		violations := 0
+		for _, l := range limits {
+			if ok, _ := path.Match(l.cfg.Package, pkg); !ok {
+				continue
+			}
+			for _, b := range benches {
+				if !l.re.MatchString(b.Name) {
+					continue
+				}
+				b.Violations = append(b.Violations, checkBenchLimit(l.cfg, b, byName)...)
+			}
+		}
+		for _, b := range benches {
+			violations += len(b.Violations)
+		}
+		res.Benchmarks = benches
+		if violations > 0 && res.Status == "pass" {
+			res.Status = "slow"
+			res.Reason = fmt.Sprintf("benchmark thresholds exceeded: %d", violations)
+		}
+	}
+}
+
+func checkBenchLimit(l config.BenchmarkLimit, b *BenchmarkResult, byName map[string]*BenchmarkResult) []string {
+	var out []string
+	if l.MaxNsPerOp > 0 && b.NsPerOp > l.MaxNsPerOp {
+		out = append(out, fmt.Sprintf("%g ns/op > %g", b.NsPerOp, l.MaxNsPerOp))
+	}
+	if l.MaxBytesPerOp > 0 && b.BytesPerOp != nil && *b.BytesPerOp > l.MaxBytesPerOp {
+		out = append(out, fmt.Sprintf("%g B/op > %g", *b.BytesPerOp, l.MaxBytesPerOp))
+	}
+	if l.MaxAllocsPerOp > 0 && b.AllocsPerOp != nil && *b.AllocsPerOp > l.MaxAllocsPerOp {
+		out = append(out, fmt.Sprintf("%g allocs/op > %g", *b.AllocsPerOp, l.MaxAllocsPerOp))
+	}
+	if l.RelativeTo != "" {
+		base, ok := byName[l.RelativeTo]
+		switch {
+		case !ok:
+			out = append(out, fmt.Sprintf("no result for %s to compare with", l.RelativeTo))
+		case base.NsPerOp > 0 && b.NsPerOp/base.NsPerOp > l.MaxRatio:
+			out = append(out, fmt.Sprintf("%.1f× slower than %s (max %g×)", b.NsPerOp/base.NsPerOp, l.RelativeTo, l.MaxRatio))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBenchCollector(t *testing.T) {
+	t.Parallel()
+
+	// строка результата может прийти несколькими событиями, -count повторяет её
+	events := `{"Action":"output","Package":"m/p","Output":"goos: linux\n"}
+{"Action":"output","Package":"m/p","Output":"BenchmarkGet/size-100-4         \t"}
+{"Action":"output","Package":"m/p","Output":" 1000\t      30.0 ns/op\t      16 B/op\t       1 allocs/op\n"}
+{"Action":"output","Package":"m/p","Output":"BenchmarkGet/size-100-4         \t 1000\t      10.0 ns/op\t      16 B/op\t       1 allocs/op\n"}
+{"Action":"output","Package":"m/p","Output":"BenchmarkGet/size-100-4         \t 1000\t      20.0 ns/op\t      16 B/op\t       1 allocs/op\n"}
+{"Action":"output","Package":"m/p","Output":"BenchmarkPut-4   \t 500\t 99 ns/op\n"}
+{"Action":"output","Package":"m/p","Output":"BenchmarkBroken-4   \t 500\t fast\n"}
+{"Action":"output","Package":"m/p","Output":"PASS\n"}`
+
+	bc := newBenchCollector(4)
+	if err := scanEvents(strings.NewReader(events), bc.add); err != nil {
+		t.Fatal(err)
+	}
+	sixteen, one := 16.0, 1.0
+	want := []*BenchmarkResult{
+		{Name: "BenchmarkGet/size-100", Runs: 3, NsPerOp: 20, BytesPerOp: &sixteen, AllocsPerOp: &one},
+		{Name: "BenchmarkPut", Runs: 1, NsPerOp: 99},
+	}
+	if got := bc.results("m/p"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("results() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestBenchProcsSuffix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		procs      int
+		line, want string
+	}{
+		{1, "BenchmarkGet/size-100 \t 10\t 5 ns/op", "BenchmarkGet/size-100"},
+		{8, "BenchmarkGet/size-100-8 \t 10\t 5 ns/op", "BenchmarkGet/size-100"},
+		{8, "BenchmarkGet-4 \t 10\t 5 ns/op", "BenchmarkGet-4"}, // -cpu=4 — другой прогон
+	}
+	for _, tt := range tests {
+		bc := newBenchCollector(tt.procs)
+		bc.parseLine("m/p", tt.line)
+		if got := bc.order["m/p"]; len(got) != 1 || got[0] != tt.want {
+			t.Errorf("procs %d: parseLine(%q) names = %q; want [%q]", tt.procs, tt.line, got, tt.want)
+		}
+	}
+}
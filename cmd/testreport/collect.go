@@ -0,0 +1,230 @@
+package main
+
+import "fmt"
+
+type TestResult struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`  // pass|fail|flaky|skip|unknown
+	Elapsed float64 `json:"elapsed"` // секунды, максимум среди прогонов (-count)
+	Runs    int     `json:"runs"`    // завершённых прогонов
+	Passed  int     `json:"passed"`
+	Failed  int     `json:"failed"`
+	Skipped int     `json:"skipped,omitempty"`
+	Output  string  `json:"output,omitempty"` // вывод первого упавшего прогона
+}
+
+// testStatus сводит прогоны теста: разные исходы при -count — flaky.
+func testStatus(r *TestResult) string {
+	switch {
+	case r.Failed > 0 && r.Passed > 0:
+		return "flaky"
+	case r.Failed > 0:
+		return "fail"
+	case r.Passed > 0:
+		return "pass"
+	case r.Skipped > 0:
+		return "skip"
+	}
+	return "unknown"
+}
+
+// testState — накопленный вывод текущего прогона теста.
+type testState struct {
+	res       *TestResult
+	output    []byte
+	truncated int
+}
+
+// collector собирает результаты по событиям go test -json.
+type collector struct {
+	maxOutput int
+	ignored   *ignoreMatcher
+	results   map[string]*PackageResult
+	tests     map[string]map[string]*testState // package → test
+	build     map[string][]byte                // ImportPath → build-output
+	diag      map[string]*diagnosis            // package → причина падения
+	seen      map[string]struct{}              // пакеты, по которым были события
+}
+
+func newCollector(ignored *ignoreMatcher, maxOutput int) *collector {
+	return &collector{
+		maxOutput: maxOutput,
+		ignored:   ignored,
+		results:   map[string]*PackageResult{},
+		tests:     map[string]map[string]*testState{},
+		build:     map[string][]byte{},
+		diag:      map[string]*diagnosis{},
+		seen:      map[string]struct{}{},
+	}
+}
+
+func (c *collector) ensure(pkg string) {
+	if pkg == "" {
+		return
+	}
+	if c.ignored.match(pkg) {
+		return
+	}
+	if _, ok := c.results[pkg]; !ok {
+		c.results[pkg] = &PackageResult{Status: "unknown"}
+	}
+}
+
+func (c *collector) add(ev TestEvent) {
+	// события сборки (go 1.24+) приходят без Package, только с ImportPath
+	switch ev.Action {
+	case "build-output":
+		c.build[ev.ImportPath] = c.appendCapped(c.build[ev.ImportPath], ev.Output)
+		return
+	case "build-fail":
+		pkg := importPathPackage(ev.ImportPath)
+		if !c.ignored.match(pkg) {
+			c.seen[pkg] = struct{}{}
+			c.ensure(pkg)
+			c.buildFailed(pkg, ev.ImportPath)
+		}
+		return
+	}
+
+	if ev.Package == "" {
+		return
+	}
+	if c.ignored.match(ev.Package) {
+		return
+	}
+	c.seen[ev.Package] = struct{}{}
+	c.ensure(ev.Package)
+	res := c.results[ev.Package]
+
+	if ev.Action == "output" {
+		c.scanOutput(ev.Package, ev.Test, ev.Output)
+	}
+
+	// package-level result: Action pass/fail/skip and empty Test
+	if ev.Test == "" {
+		switch ev.Action {
+		case "pass":
+			res.Status = "pass"
+			res.Elapsed = ev.Elapsed
+		case "fail":
+			res.Elapsed = ev.Elapsed
+			if ev.FailedBuild != "" {
+				c.buildFailed(ev.Package, ev.FailedBuild)
+				return
+			}
+			if res.Status != "build_failed" {
+				res.Status = "fail"
+			}
+		case "skip":
+			// sometimes packages get skipped; keep it explicit
+			if res.Status == "unknown" {
+				res.Status = "skip"
+			}
+		}
+		return
+	}
+
+	st := c.test(ev.Package, ev.Test)
+	switch ev.Action {
+	case "run":
+		// новый прогон (-count): вывод предыдущего уже не нужен
+		st.output, st.truncated = st.output[:0], 0
+	case "output":
+		c.capture(st, ev.Output)
+	case "pass", "fail", "skip":
+		st.res.Elapsed = max(st.res.Elapsed, ev.Elapsed)
+		st.res.Runs++
+		switch ev.Action {
+		case "pass":
+			st.res.Passed++
+		case "fail":
+			if st.res.Failed == 0 {
+				st.res.Output = st.text()
+				// test-level fail: Action fail and Test present; при -count — один раз
+				res.FailedTests = append(res.FailedTests, ev.Test)
+			}
+			st.res.Failed++
+		case "skip":
+			st.res.Skipped++
+		}
+		st.res.Status = testStatus(st.res)
+		st.output, st.truncated = st.output[:0], 0
+	}
+}
+
+// finish уточняет статусы упавших пакетов: причина из вывода (panic, timeout,
+// data race), иначе flaky, если пакет упал только из-за flaky-тестов.
+func (c *collector) finish() {
+	for pkg, res := range c.results {
+		if c.applyDiagnosis(pkg, res) || res.Status != "fail" {
+			continue
+		}
+		failed, flaky := 0, 0
+		for _, t := range res.Tests {
+			if t.Failed > 0 {
+				failed++
+			}
+			if t.Status == "flaky" {
+				flaky++
+			}
+		}
+		if failed > 0 && failed == flaky {
+			res.Status = "flaky"
+		}
+	}
+}
+
+// missing — ожидаемые пакеты (-pkgs), по которым не пришло ни одного события:
+// go test их не запускал (упал раньше, -failfast, неверный список пакетов).
+func (c *collector) missing(expected []string) []string {
+	var out []string
+	for _, pkg := range expected {
+		if _, ok := c.results[pkg]; !ok {
+			continue // игнорируется
+		}
+		if _, ok := c.seen[pkg]; !ok {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+func (c *collector) test(pkg, name string) *testState {
+	byName := c.tests[pkg]
+	if byName == nil {
+		byName = map[string]*testState{}
+		c.tests[pkg] = byName
+	}
+	st, ok := byName[name]
+	if !ok {
+		st = &testState{res: &TestResult{Name: name, Status: "unknown"}}
+		byName[name] = st
+		c.results[pkg].Tests = append(c.results[pkg].Tests, st.res)
+	}
+	return st
+}
+
+// capture копит вывод теста, но не больше maxOutput байт; при maxOutput <= 0
+// вывод не сохраняется вовсе, без пометки об обрезке.
+func (c *collector) capture(st *testState, out string) {
+	if c.maxOutput <= 0 {
+		return
+	}
+	room := c.maxOutput - len(st.output)
+	if room <= 0 {
+		st.truncated += len(out)
+		return
+	}
+	if len(out) > room {
+		st.truncated += len(out) - room
+		out = out[:room]
+	}
+	st.output = append(st.output, out...)
+}
+
+func (st *testState) text() string {
+	if st.truncated > 0 {
+		return string(st.output) + fmt.Sprintf("\n... (%d bytes truncated)\n", st.truncated)
+	}
+	return string(st.output)
+}
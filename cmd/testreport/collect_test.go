@@ -0,0 +1,160 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// collectEvents прогоняет через collector события go test -json (по одному на строку).
+func collectEvents(t *testing.T, events string, ignore ...string) *collector {
+	t.Helper()
+	ignored, err := newIgnoreMatcher(ignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := newCollector(ignored, 8192)
+	if err := scanEvents(strings.NewReader(events), col.add); err != nil {
+		t.Fatal(err)
+	}
+	col.finish()
+	return col
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		events      string
+		status      string
+		failedTests []string
+		tests       []TestResult
+	}{
+		{
+			name: "pass",
+			events: `{"Action":"start","Package":"m/p"}
+{"Action":"run","Package":"m/p","Test":"TestA"}
+{"Action":"output","Package":"m/p","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"pass","Package":"m/p","Test":"TestA","Elapsed":0.5}
+{"Action":"pass","Package":"m/p","Elapsed":0.7}`,
+			status: "pass",
+			tests:  []TestResult{{Name: "TestA", Status: "pass", Elapsed: 0.5, Runs: 1, Passed: 1}},
+		},
+		{
+			name: "failing test with -count is listed once",
+			events: `{"Action":"run","Package":"m/p","Test":"TestBad"}
+{"Action":"output","Package":"m/p","Test":"TestBad","Output":"    x_test.go:5: boom\n"}
+{"Action":"fail","Package":"m/p","Test":"TestBad","Elapsed":0.1}
+{"Action":"run","Package":"m/p","Test":"TestBad"}
+{"Action":"output","Package":"m/p","Test":"TestBad","Output":"    x_test.go:5: boom again\n"}
+{"Action":"fail","Package":"m/p","Test":"TestBad","Elapsed":0.3}
+{"Action":"fail","Package":"m/p","Elapsed":0.5}`,
+			status:      "fail",
+			failedTests: []string{"TestBad"},
+			tests:       []TestResult{{Name: "TestBad", Status: "fail", Elapsed: 0.3, Runs: 2, Failed: 2, Output: "    x_test.go:5: boom\n"}},
+		},
+		{
+			name: "mixed runs make test and package flaky",
+			events: `{"Action":"run","Package":"m/p","Test":"TestFlaky"}
+{"Action":"output","Package":"m/p","Test":"TestFlaky","Output":"    x_test.go:9: unlucky\n"}
+{"Action":"fail","Package":"m/p","Test":"TestFlaky","Elapsed":0.1}
+{"Action":"run","Package":"m/p","Test":"TestFlaky"}
+{"Action":"output","Package":"m/p","Test":"TestFlaky","Output":"lucky\n"}
+{"Action":"pass","Package":"m/p","Test":"TestFlaky","Elapsed":0.2}
+{"Action":"run","Package":"m/p","Test":"TestOK"}
+{"Action":"pass","Package":"m/p","Test":"TestOK"}
+{"Action":"pass","Package":"m/p","Test":"TestOK"}
+{"Action":"fail","Package":"m/p","Elapsed":0.5}`,
+			status:      "flaky",
+			failedTests: []string{"TestFlaky"},
+			tests: []TestResult{
+				{Name: "TestFlaky", Status: "flaky", Elapsed: 0.2, Runs: 2, Passed: 1, Failed: 1, Output: "    x_test.go:9: unlucky\n"},
+				{Name: "TestOK", Status: "pass", Runs: 2, Passed: 2},
+			},
+		},
+		{
+			name: "flaky and failing test: package fails",
+			events: `{"Action":"fail","Package":"m/p","Test":"TestFlaky"}
+{"Action":"pass","Package":"m/p","Test":"TestFlaky"}
+{"Action":"fail","Package":"m/p","Test":"TestBad"}
+{"Action":"fail","Package":"m/p"}`,
+			status:      "fail",
+			failedTests: []string{"TestFlaky", "TestBad"},
+			tests: []TestResult{
+				{Name: "TestFlaky", Status: "flaky", Runs: 2, Passed: 1, Failed: 1},
+				{Name: "TestBad", Status: "fail", Runs: 1, Failed: 1},
+			},
+		},
+		{
+			name: "unfinished test",
+			events: `{"Action":"run","Package":"m/p","Test":"TestHang"}
+{"Action":"output","Package":"m/p","Test":"TestHang","Output":"=== RUN   TestHang\n"}`,
+			status: "unknown",
+			tests:  []TestResult{{Name: "TestHang", Status: "unknown"}},
+		},
+		{
+			name: "no test files",
+			events: `{"Action":"start","Package":"m/p"}
+{"Action":"output","Package":"m/p","Output":"?   \tm/p\t[no test files]\n"}
+{"Action":"skip","Package":"m/p","Elapsed":0}`,
+			status: "skip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := collectEvents(t, tt.events).results["m/p"]
+			if res == nil {
+				t.Fatal("no result for m/p")
+			}
+			if res.Status != tt.status {
+				t.Errorf("status = %q; want %q", res.Status, tt.status)
+			}
+			if !reflect.DeepEqual(res.FailedTests, tt.failedTests) {
+				t.Errorf("failed_tests = %q; want %q", res.FailedTests, tt.failedTests)
+			}
+			var got []TestResult
+			for _, tr := range res.Tests {
+				got = append(got, *tr)
+			}
+			if !reflect.DeepEqual(got, tt.tests) {
+				t.Errorf("tests =\n%+v\nwant\n%+v", got, tt.tests)
+			}
+		})
+	}
+}
+
+func TestCollectorMaxOutput(t *testing.T) {
+	t.Parallel()
+
+	events := `{"Action":"run","Package":"m/p","Test":"TestBad"}
+{"Action":"output","Package":"m/p","Test":"TestBad","Output":"    x_test.go:5: 0123456789\n"}
+{"Action":"fail","Package":"m/p","Test":"TestBad"}
+{"Action":"fail","Package":"m/p"}`
+
+	tests := []struct {
+		maxOutput int
+		want      string
+	}{
+		{0, ""},
+		{-1, ""},
+		{14, "    x_test.go:\n... (14 bytes truncated)\n"},
+		{8192, "    x_test.go:5: 0123456789\n"},
+	}
+	for _, tt := range tests {
+		ignored, err := newIgnoreMatcher(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		col := newCollector(ignored, tt.maxOutput)
+		if err := scanEvents(strings.NewReader(events), col.add); err != nil {
+			t.Fatal(err)
+		}
+		col.finish()
+		if got := col.results["m/p"].Tests[0].Output; got != tt.want {
+			t.Errorf("max-output %d: output = %q; want %q", tt.maxOutput, got, tt.want)
+		}
+	}
+}
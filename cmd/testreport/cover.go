@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"industry_backend_go/internal/gomod"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// solutionFile — файл решения задачи: по нему считаем покрытие функций.
+const solutionFile = "solution.go"
+
+type Coverage struct {
+	Statements int            `json:"statements"`
+	Covered    int            `json:"covered"`
+	Percent    float64        `json:"percent"`
+	Functions  []FuncCoverage `json:"functions,omitempty"` // функции solution.go
+	Uncovered  []string       `json:"uncovered,omitempty"` // непокрытые блоки solution.go, "solution.go:12.5-14.3"
+}
+
+type FuncCoverage struct {
+	Name       string  `json:"name"`
+	Line       int     `json:"line"`
+	Statements int     `json:"statements"`
+	Covered    int     `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// coverBlock — строка coverprofile: file:l0.c0,l1.c1 stmts count.
+type coverBlock struct {
+	file                   string // import path файла
+	line0, col0, line1, c1 int
+	stmts, count           int
+}
+
+func (b coverBlock) key() string {
+	return fmt.Sprintf("%s:%d.%d,%d.%d", b.file, b.line0, b.col0, b.line1, b.c1)
+}
+
+// readCoverProfile читает go test -coverprofile. Один блок может встретиться
+// несколько раз (-coverpkg, склеенные профили) — счётчики складываем.
+func readCoverProfile(p string) ([]coverBlock, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byKey := map[string]int{}
+	var out []coverBlock
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		b, err := parseCoverLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", p, n, err)
+		}
+		if i, ok := byKey[b.key()]; ok {
+			out[i].count += b.count
+			continue
+		}
+		byKey[b.key()] = len(out)
+		out = append(out, b)
+	}
+	return out, sc.Err()
+}
+
+func parseCoverLine(line string) (coverBlock, error) {
+	var b coverBlock
+	colon := strings.LastIndex(line, ":")
+	if colon < 0 {
+		return b, fmt.Errorf("bad coverprofile line %q", line)
+	}
+	b.file = line[:colon]
+	_, err := fmt.Sscanf(line[colon+1:], "%d.%d,%d.%d %d %d", &b.line0, &b.col0, &b.line1, &b.c1, &b.stmts, &b.count)
+	if err != nil {
+		return b, fmt.Errorf("bad coverprofile line %q: %w", line, err)
+	}
+	return b, nil
+}
+
+// applyCoverage считает покрытие по пакетам, а для solution.go — ещё по функциям.
+func applyCoverage(blocks []coverBlock, results map[string]*PackageResult) {
+	module := gomod.ModulePath("go.mod")
+	byPkg := map[string][]coverBlock{}
+	for _, b := range blocks {
+		pkg := path.Dir(b.file)
+		byPkg[pkg] = append(byPkg[pkg], b)
+	}
+
+	for pkg, pkgBlocks := range byPkg {
+		res, ok := results[pkg]
+		if !ok {
+			continue
+		}
+		cov := &Coverage{}
+		var solution []coverBlock
+		for _, b := range pkgBlocks {
+			cov.Statements += b.stmts
+			if b.count > 0 {
+				cov.Covered += b.stmts
+			}
+			if path.Base(b.file) == solutionFile {
+				solution = append(solution, b)
+			}
+		}
+		cov.Percent = percent(cov.Covered, cov.Statements)
+
+		if len(solution) > 0 {
+			sort.Slice(solution, func(i, j int) bool {
+				if solution[i].line0 != solution[j].line0 {
+					return solution[i].line0 < solution[j].line0
+				}
+				return solution[i].col0 < solution[j].col0
+			})
+			for _, b := range solution {
+				if b.count == 0 {
+					cov.Uncovered = append(cov.Uncovered, fmt.Sprintf("%s:%d.%d-%d.%d", solutionFile, b.line0, b.col0, b.line1, b.c1))
+				}
+			}
+			src, ok := strings.CutPrefix(solution[0].file, module+"/")
+			if module != "" && ok {
+				funcs, err := funcCoverage(filepath.FromSlash(src), solution)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: coverage of %s: %v\n", src, err)
+				}
+				cov.Functions = funcs
+			}
+		}
+		res.Coverage = cov
+	}
+}
+
+// funcCoverage раскладывает блоки файла по функциям (как go tool cover -func).
+func funcCoverage(file string, blocks []coverBlock) ([]FuncCoverage, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []FuncCoverage
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		fc := FuncCoverage{Name: funcName(fn), Line: start.Line}
+		for _, b := range blocks {
+			if posBefore(b.line0, b.col0, start) || posAfter(b.line1, b.c1, end) {
+				continue
+			}
+			fc.Statements += b.stmts
+			if b.count > 0 {
+				fc.Covered += b.stmts
+			}
+		}
+		fc.Percent = percent(fc.Covered, fc.Statements)
+		out = append(out, fc)
+	}
+	return out, nil
+}
+
+func posBefore(line, col int, pos token.Position) bool {
+	return line < pos.Line || line == pos.Line && col < pos.Column
+}
+
+func posAfter(line, col int, pos token.Position) bool {
+	return line > pos.Line || line == pos.Line && col > pos.Column
+}
+
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(covered)/float64(total)) / 10
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCoverLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line string
+		want coverBlock
+		ok   bool
+	}{
+		{"m/tasks/task_01/solution.go:3.24,5.2 1 4", coverBlock{file: "m/tasks/task_01/solution.go", line0: 3, col0: 24, line1: 5, c1: 2, stmts: 1, count: 4}, true},
+		{"m/p/x.go:10.2,10.15 2 0", coverBlock{file: "m/p/x.go", line0: 10, col0: 2, line1: 10, c1: 15, stmts: 2}, true},
+		{"no colon here", coverBlock{}, false},
+		{"m/p/x.go:10.2 2 0", coverBlock{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseCoverLine(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseCoverLine(%q) error = %v; want ok %v", tt.line, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("parseCoverLine(%q) = %+v; want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestFuncCoverage(t *testing.T) {
+	t.Parallel()
+
+	src := `package p
+
+func Add(a, b int) int {
+	return a + b
+}
+
+type S struct{}
+
+func (s *S) Get(x int) int {
+	if x > 0 {
+		return x
+	}
+	return 0
+}
+`
+	file := filepath.Join(t.TempDir(), solutionFile)
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// блоки в том виде, как их пишет go test -coverprofile
+	blocks := []coverBlock{
+		{line0: 3, col0: 24, line1: 5, c1: 2, stmts: 1, count: 0},
+		{line0: 9, col0: 28, line1: 10, c1: 11, stmts: 1, count: 3},
+		{line0: 13, col0: 2, line1: 13, c1: 10, stmts: 1, count: 1},
+		{line0: 10, col0: 11, line1: 12, c1: 3, stmts: 1, count: 0},
+	}
+
+	got, err := funcCoverage(file, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FuncCoverage{
+		{Name: "Add", Line: 3, Statements: 1, Covered: 0, Percent: 0},
+		{Name: "S.Get", Line: 9, Statements: 3, Covered: 2, Percent: 66.7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("funcCoverage() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+)
+
+// stackHeadLines — сколько строк после panic/race-отчёта сохраняем в details.
+const stackHeadLines = 20
+
+// Причины падения пакета в порядке важности: timeout — тоже panic,
+// но о нём полезнее сказать отдельно.
+var failureRank = map[string]int{
+	"data_race":    1,
+	"panic":        2,
+	"timeout":      3,
+	"build_failed": 4,
+}
+
+// diagnosis — найденная в выводе пакета причина падения.
+type diagnosis struct {
+	kind    string
+	reason  string
+	details []byte
+	capture int // сколько строк ещё дописать в details
+}
+
+// scanOutput ищет в строке вывода panic, timeout и отчёт race detector.
+func (c *collector) scanOutput(pkg, test, out string) {
+	d := c.diag[pkg]
+	if d != nil && d.capture > 0 {
+		d.capture--
+		d.details = c.appendCapped(d.details, out)
+	}
+
+	line := strings.TrimSpace(out)
+	var kind, reason string
+	switch {
+	case strings.HasPrefix(line, "panic: test timed out"):
+		kind, reason = "timeout", strings.TrimPrefix(line, "panic: ")
+	case strings.HasPrefix(line, "panic: "):
+		kind, reason = "panic", line
+	case line == "WARNING: DATA RACE":
+		kind, reason = "data_race", "data race detected"
+	case strings.HasPrefix(line, "FAIL") && (strings.HasSuffix(line, "[build failed]") || strings.HasSuffix(line, "[setup failed]")):
+		// старые версии go пишут ошибки сборки в stderr, а в -json остаётся только эта строка
+		kind, reason = "build_failed", line
+	default:
+		return
+	}
+	if d != nil && failureRank[d.kind] >= failureRank[kind] {
+		return
+	}
+	if test != "" {
+		reason += " (in " + test + ")"
+	}
+	c.diag[pkg] = &diagnosis{
+		kind:    kind,
+		reason:  reason,
+		details: c.appendCapped(nil, out),
+		capture: stackHeadLines,
+	}
+}
+
+// buildFailed помечает пакет, который не собрался; сообщения компилятора
+// берутся из событий build-output с тем же ImportPath.
+func (c *collector) buildFailed(pkg, importPath string) {
+	res := c.results[pkg]
+	if res == nil {
+		return
+	}
+	res.Status = "build_failed"
+	out := string(c.build[importPath])
+	res.Details = out
+	res.Reason = "build failed: " + importPath
+	for _, l := range strings.Split(out, "\n") {
+		// первая строка — "# pkg [pkg.test]", за ней сообщения компилятора
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+			res.Reason = "build failed: " + l
+			break
+		}
+	}
+}
+
+// applyDiagnosis уточняет статус упавшего пакета найденной причиной.
+func (c *collector) applyDiagnosis(pkg string, res *PackageResult) bool {
+	d := c.diag[pkg]
+	if d == nil || (res.Status != "fail" && res.Status != "unknown") {
+		return false
+	}
+	res.Status = d.kind
+	res.Reason = d.reason
+	res.Details = string(d.details)
+	return true
+}
+
+func (c *collector) appendCapped(b []byte, s string) []byte {
+	if room := c.maxOutput - len(b); room < len(s) {
+		s = s[:max(room, 0)]
+	}
+	return append(b, s...)
+}
+
+// importPathPackage: "pkg [pkg.test]" → "pkg".
+func importPathPackage(importPath string) string {
+	pkg, _, _ := strings.Cut(importPath, " ")
+	return pkg
+}
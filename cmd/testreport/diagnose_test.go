@@ -0,0 +1,174 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		events  string
+		status  string
+		reason  string
+		details string // подстрока details
+	}{
+		{
+			name: "panic",
+			events: `{"Action":"run","Package":"m/p","Test":"TestNil"}
+{"Action":"output","Package":"m/p","Test":"TestNil","Output":"panic: runtime error: invalid memory address or nil pointer dereference [recovered]\n"}
+{"Action":"output","Package":"m/p","Test":"TestNil","Output":"goroutine 7 [running]:\n"}
+{"Action":"fail","Package":"m/p","Test":"TestNil","Elapsed":0}
+{"Action":"output","Package":"m/p","Output":"FAIL\tm/p\t0.010s\n"}
+{"Action":"fail","Package":"m/p","Elapsed":0.01}`,
+			status:  "panic",
+			reason:  "panic: runtime error: invalid memory address or nil pointer dereference [recovered] (in TestNil)",
+			details: "goroutine 7 [running]:",
+		},
+		{
+			name: "timeout",
+			events: `{"Action":"run","Package":"m/p","Test":"TestSlow"}
+{"Action":"output","Package":"m/p","Test":"TestSlow","Output":"panic: test timed out after 1s\n"}
+{"Action":"output","Package":"m/p","Test":"TestSlow","Output":"\trunning tests:\n"}
+{"Action":"output","Package":"m/p","Test":"TestSlow","Output":"\t\tTestSlow (1s)\n"}
+{"Action":"fail","Package":"m/p","Test":"TestSlow","Elapsed":1}
+{"Action":"fail","Package":"m/p","Elapsed":1}`,
+			status:  "timeout",
+			reason:  "test timed out after 1s (in TestSlow)",
+			details: "TestSlow (1s)",
+		},
+		{
+			name: "data race",
+			events: `{"Action":"run","Package":"m/p","Test":"TestRace"}
+{"Action":"output","Package":"m/p","Test":"TestRace","Output":"==================\n"}
+{"Action":"output","Package":"m/p","Test":"TestRace","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"m/p","Test":"TestRace","Output":"Write at 0x00c000012345 by goroutine 8:\n"}
+{"Action":"output","Package":"m/p","Test":"TestRace","Output":"    testing.go:1490: race detected during execution of test\n"}
+{"Action":"fail","Package":"m/p","Test":"TestRace","Elapsed":0}
+{"Action":"fail","Package":"m/p","Elapsed":0.02}`,
+			status:  "data_race",
+			reason:  "data race detected (in TestRace)",
+			details: "Write at 0x00c000012345 by goroutine 8:",
+		},
+		{
+			name: "build failure without build events (go < 1.24)",
+			events: `{"Action":"start","Package":"m/p"}
+{"Action":"output","Package":"m/p","Output":"FAIL\tm/p [build failed]\n"}
+{"Action":"fail","Package":"m/p","Elapsed":0}`,
+			status: "build_failed",
+			reason: "FAIL\tm/p [build failed]",
+		},
+		{
+			name: "plain failure is not diagnosed",
+			events: `{"Action":"run","Package":"m/p","Test":"TestBad"}
+{"Action":"output","Package":"m/p","Test":"TestBad","Output":"    x_test.go:5: got 1, want 2\n"}
+{"Action":"fail","Package":"m/p","Test":"TestBad","Elapsed":0}
+{"Action":"fail","Package":"m/p","Elapsed":0}`,
+			status: "fail",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := collectEvents(t, tt.events).results["m/p"]
+			if res.Status != tt.status || res.Reason != tt.reason {
+				t.Errorf("status, reason = %q, %q; want %q, %q", res.Status, res.Reason, tt.status, tt.reason)
+			}
+			if !strings.Contains(res.Details, tt.details) {
+				t.Errorf("details = %q; want to contain %q", res.Details, tt.details)
+			}
+		})
+	}
+}
+
+// События сборки в go 1.24+: build-output/build-fail по ImportPath и
+// FailedBuild в итоговом fail пакета.
+func TestDiagnoseFailedBuild(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		events  string
+		status  map[string]string
+		reason  string
+		details string
+	}{
+		{
+			name: "test binary does not compile",
+			events: `{"ImportPath":"m/p [m/p.test]","Action":"build-output","Output":"# m/p [m/p.test]\n"}
+{"ImportPath":"m/p [m/p.test]","Action":"build-output","Output":"./x_test.go:5:2: undefined: foo\n"}
+{"ImportPath":"m/p [m/p.test]","Action":"build-fail"}
+{"Action":"start","Package":"m/p"}
+{"Action":"output","Package":"m/p","Output":"FAIL\tm/p [build failed]\n"}
+{"Action":"fail","Package":"m/p","Elapsed":0,"FailedBuild":"m/p [m/p.test]"}`,
+			status:  map[string]string{"m/p": "build_failed"},
+			reason:  "build failed: ./x_test.go:5:2: undefined: foo",
+			details: "undefined: foo",
+		},
+		{
+			name: "dependency does not compile",
+			events: `{"ImportPath":"m/dep","Action":"build-output","Output":"# m/dep\n"}
+{"ImportPath":"m/dep","Action":"build-output","Output":"dep/dep.go:3:1: syntax error: non-declaration statement outside function body\n"}
+{"ImportPath":"m/dep","Action":"build-fail"}
+{"Action":"start","Package":"m/p"}
+{"Action":"output","Package":"m/p","Output":"FAIL\tm/p [build failed]\n"}
+{"Action":"fail","Package":"m/p","Elapsed":0,"FailedBuild":"m/dep"}`,
+			status:  map[string]string{"m/p": "build_failed", "m/dep": "build_failed"},
+			reason:  "build failed: dep/dep.go:3:1: syntax error: non-declaration statement outside function body",
+			details: "# m/dep",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			results := collectEvents(t, tt.events).results
+			for pkg, want := range tt.status {
+				if res := results[pkg]; res == nil || res.Status != want {
+					t.Errorf("%s: result %+v; want status %q", pkg, res, want)
+				}
+			}
+			res := results["m/p"]
+			if res.Reason != tt.reason {
+				t.Errorf("reason = %q; want %q", res.Reason, tt.reason)
+			}
+			if !strings.Contains(res.Details, tt.details) {
+				t.Errorf("details = %q; want to contain %q", res.Details, tt.details)
+			}
+		})
+	}
+}
+
+// Если в выводе несколько причин, остаётся самая важная по failureRank:
+// build_failed > timeout > panic > data_race, независимо от порядка.
+func TestDiagnoseRank(t *testing.T) {
+	t.Parallel()
+
+	const (
+		race     = `{"Action":"output","Package":"m/p","Test":"TestX","Output":"WARNING: DATA RACE\n"}`
+		panicked = `{"Action":"output","Package":"m/p","Test":"TestX","Output":"panic: boom\n"}`
+		timeout  = `{"Action":"output","Package":"m/p","Output":"panic: test timed out after 10m0s\n"}`
+		build    = `{"Action":"output","Package":"m/p","Output":"FAIL\tm/p [setup failed]\n"}`
+		fail     = `{"Action":"fail","Package":"m/p"}`
+	)
+	tests := []struct {
+		events []string
+		want   string
+	}{
+		{[]string{race, panicked}, "panic"},
+		{[]string{panicked, race}, "panic"},
+		{[]string{panicked, timeout}, "timeout"},
+		{[]string{timeout, panicked, race}, "timeout"},
+		{[]string{race, timeout, build}, "build_failed"},
+		{[]string{build, race}, "build_failed"},
+	}
+	for _, tt := range tests {
+		events := strings.Join(append(tt.events, fail), "\n")
+		if got := collectEvents(t, events).results["m/p"].Status; got != tt.want {
+			t.Errorf("status after\n%s\n= %q; want %q", events, got, tt.want)
+		}
+	}
+}
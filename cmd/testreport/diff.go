@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// diffEntry — пакет или тест, у которого изменился исход между отчётами.
+type diffEntry struct {
+	Package string
+	Test    string
+	Old     string
+	New     string
+	Reason  string
+}
+
+func (e diffEntry) String() string {
+	name := e.Package
+	if e.Test != "" {
+		name += " " + e.Test
+	}
+	s := fmt.Sprintf("%s (%s → %s)", name, e.Old, e.New)
+	if e.Reason != "" {
+		s += ": " + e.Reason
+	}
+	return s
+}
+
+type reportDiff struct {
+	NewlyFailing []diffEntry
+	NewlyPassing []diffEntry
+	NewlyFlaky   []diffEntry
+	NewlySkipped []diffEntry // был pass/fail/flaky, стал skip
+	Disappeared  []diffEntry
+}
+
+// regressions: пропуск (t.Skip) и удаление теста тоже регрессии — так
+// проще всего спрятать падение.
+func (d reportDiff) regressions() int {
+	return len(d.NewlyFailing) + len(d.NewlyFlaky) + len(d.NewlySkipped) + len(d.Disappeared)
+}
+
+// runDiff: testreport diff old.json new.json. Код возврата 1 — есть регрессии
+// (новые падения, flaky, пропущенные или пропавшие тесты и пакеты), 2 — ошибка.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: testreport diff [flags] old.json new.json")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	oldRes, err := readResults(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read old report: %v\n", err)
+		return 2
+	}
+	newRes, err := readResults(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read new report: %v\n", err)
+		return 2
+	}
+
+	d := diffResults(oldRes, newRes)
+	printSection("newly failing", d.NewlyFailing)
+	printSection("newly flaky", d.NewlyFlaky)
+	printSection("newly skipped", d.NewlySkipped)
+	printSection("disappeared", d.Disappeared)
+	printSection("newly passing", d.NewlyPassing)
+
+	if d.regressions() > 0 {
+		return 1
+	}
+	fmt.Println("no regressions")
+	return 0
+}
+
+func readResults(p string) (map[string]*PackageResult, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]*PackageResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func printSection(title string, entries []diffEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Printf("%s: %d\n", title, len(entries))
+	for _, e := range entries {
+		fmt.Printf("  %s\n", e)
+	}
+}
+
+// outcome сводит статус к pass|skip|flaky|fail: build_failed, panic,
+// timeout, data_race и unknown — это тоже fail.
+func outcome(status string) string {
+	switch status {
+	case "pass", "skip", "flaky":
+		return status
+	}
+	return "fail"
+}
+
+func diffResults(oldRes, newRes map[string]*PackageResult) reportDiff {
+	var d reportDiff
+	classify := func(e diffEntry) {
+		o, n := outcome(e.Old), outcome(e.New)
+		switch {
+		case o == n:
+		case n == "fail":
+			d.NewlyFailing = append(d.NewlyFailing, e)
+		case n == "flaky":
+			d.NewlyFlaky = append(d.NewlyFlaky, e)
+		case n == "skip":
+			d.NewlySkipped = append(d.NewlySkipped, e)
+		case n == "pass" && (o == "fail" || o == "flaky"):
+			d.NewlyPassing = append(d.NewlyPassing, e)
+		}
+	}
+	// новые пакеты и тесты интересны, только если они падают
+	classifyAdded := func(e diffEntry) {
+		switch outcome(e.New) {
+		case "fail":
+			d.NewlyFailing = append(d.NewlyFailing, e)
+		case "flaky":
+			d.NewlyFlaky = append(d.NewlyFlaky, e)
+		}
+	}
+
+	for _, pkg := range sortedPackages(oldRes) {
+		o := oldRes[pkg]
+		n, ok := newRes[pkg]
+		if !ok {
+			d.Disappeared = append(d.Disappeared, diffEntry{Package: pkg, Old: o.Status, New: "missing"})
+			continue
+		}
+		classify(diffEntry{Package: pkg, Old: o.Status, New: n.Status, Reason: n.Reason})
+
+		newTests := map[string]*TestResult{}
+		for _, t := range n.Tests {
+			newTests[t.Name] = t
+		}
+		oldTests := append([]*TestResult(nil), o.Tests...)
+		sort.Slice(oldTests, func(i, j int) bool { return oldTests[i].Name < oldTests[j].Name })
+		for _, ot := range oldTests {
+			nt, ok := newTests[ot.Name]
+			if !ok {
+				d.Disappeared = append(d.Disappeared, diffEntry{Package: pkg, Test: ot.Name, Old: ot.Status, New: "missing"})
+				continue
+			}
+			classify(diffEntry{Package: pkg, Test: ot.Name, Old: ot.Status, New: nt.Status})
+			delete(newTests, ot.Name)
+		}
+		for _, nt := range n.Tests {
+			if _, added := newTests[nt.Name]; added {
+				classifyAdded(diffEntry{Package: pkg, Test: nt.Name, Old: "missing", New: nt.Status})
+			}
+		}
+	}
+
+	for _, pkg := range sortedPackages(newRes) {
+		if _, ok := oldRes[pkg]; ok {
+			continue
+		}
+		n := newRes[pkg]
+		classifyAdded(diffEntry{Package: pkg, Old: "missing", New: n.Status, Reason: n.Reason})
+	}
+	return d
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	t.Parallel()
+
+	tr := func(name, status string) *TestResult { return &TestResult{Name: name, Status: status} }
+	oldRes := map[string]*PackageResult{
+		"m/a": {Status: "pass", Tests: []*TestResult{tr("TestA", "pass"), tr("TestGone", "pass")}},
+		"m/b": {Status: "fail", Tests: []*TestResult{tr("TestB", "fail")}},
+		"m/c": {Status: "pass", Tests: []*TestResult{tr("TestC", "pass")}},
+		"m/d": {Status: "pass"},
+		"m/g": {Status: "fail", Tests: []*TestResult{tr("TestPass", "pass"), tr("TestFail", "fail"), tr("TestSkip", "skip")}},
+		"m/h": {Status: "pass"},
+	}
+	newRes := map[string]*PackageResult{
+		"m/a": {Status: "panic", Reason: "panic: boom", Tests: []*TestResult{tr("TestA", "fail"), tr("TestNew", "pass")}},
+		"m/b": {Status: "pass", Tests: []*TestResult{tr("TestB", "pass")}},
+		"m/c": {Status: "flaky", Tests: []*TestResult{tr("TestC", "flaky"), tr("TestNewBad", "fail")}},
+		"m/e": {Status: "build_failed", Reason: "build failed: x.go:1: syntax error"},
+		"m/f": {Status: "pass"},
+		"m/g": {Status: "pass", Tests: []*TestResult{tr("TestPass", "skip"), tr("TestFail", "skip"), tr("TestSkip", "pass")}},
+		"m/h": {Status: "skip"},
+	}
+
+	got := diffResults(oldRes, newRes)
+	want := reportDiff{
+		NewlyFailing: []diffEntry{
+			{Package: "m/a", Old: "pass", New: "panic", Reason: "panic: boom"},
+			{Package: "m/a", Test: "TestA", Old: "pass", New: "fail"},
+			{Package: "m/c", Test: "TestNewBad", Old: "missing", New: "fail"},
+			{Package: "m/e", Old: "missing", New: "build_failed", Reason: "build failed: x.go:1: syntax error"},
+		},
+		NewlyPassing: []diffEntry{
+			{Package: "m/b", Old: "fail", New: "pass"},
+			{Package: "m/b", Test: "TestB", Old: "fail", New: "pass"},
+			{Package: "m/g", Old: "fail", New: "pass"},
+		},
+		NewlyFlaky: []diffEntry{
+			{Package: "m/c", Old: "pass", New: "flaky"},
+			{Package: "m/c", Test: "TestC", Old: "pass", New: "flaky"},
+		},
+		NewlySkipped: []diffEntry{
+			{Package: "m/g", Test: "TestFail", Old: "fail", New: "skip"},
+			{Package: "m/g", Test: "TestPass", Old: "pass", New: "skip"},
+			{Package: "m/h", Old: "pass", New: "skip"},
+		},
+		Disappeared: []diffEntry{
+			{Package: "m/a", Test: "TestGone", Old: "pass", New: "missing"},
+			{Package: "m/d", Old: "pass", New: "missing"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffResults() =\n%+v\nwant\n%+v", got, want)
+	}
+	if got.regressions() != 11 {
+		t.Errorf("regressions() = %d; want 11", got.regressions())
+	}
+}
+
+func TestDiffRegressions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     int
+	}{
+		{"newly passing only", "fail", "pass", 0},
+		{"skip to pass", "skip", "pass", 0},
+		{"newly skipped", "pass", "skip", 1},
+		{"failing test skipped", "fail", "skip", 1},
+		{"newly flaky", "pass", "flaky", 1},
+		{"disappeared", "pass", "", 1},
+	}
+	for _, tt := range tests {
+		oldRes := map[string]*PackageResult{"m/p": {Status: tt.old}}
+		newRes := map[string]*PackageResult{}
+		if tt.new != "" {
+			newRes["m/p"] = &PackageResult{Status: tt.new}
+		}
+		if got := diffResults(oldRes, newRes).regressions(); got != tt.want {
+			t.Errorf("%s: regressions() = %d; want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"industry_backend_go/internal/outfmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// reportFormats — форматы отчёта testreport (-format).
+var reportFormats = outfmt.Set{
+	{Name: "json", Ext: ".json"},
+	{Name: "junit", Ext: ".xml"},
+	{Name: "tap", Ext: ".tap"},
+	{Name: "html", Ext: ".html"},
+}
+
+// ignoredMessage — пометка пакетов из tests.ignore_packages в junit и tap;
+// в json-отчёте только пакеты с результатами, список — в -packages-out.
+const ignoredMessage = "ignored by tests.ignore_packages"
+
+func writeReport(p, format string, results map[string]*PackageResult, ignored []string, readmeBase string) error {
+	var b []byte
+	var err error
+	switch format {
+	case "junit":
+		b, err = xml.MarshalIndent(toJUnit(results, ignored), "", "  ")
+		b = append([]byte(xml.Header), append(b, '\n')...)
+	case "tap":
+		b = toTAP(results, ignored)
+	case "html":
+		b, err = toHTML(results, ignored, readmeBase)
+	default:
+		b, err = json.MarshalIndent(results, "", "  ")
+		b = append(b, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o644)
+}
+
+func sortedPackages(results map[string]*PackageResult) []string {
+	pkgs := make([]string, 0, len(results))
+	for p := range results {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// testFailed — тест считается упавшим, если упал хотя бы один прогон
+// или так и не завершился.
+func testFailed(t *TestResult) bool {
+	return t.Status == "fail" || t.Status == "flaky" || t.Status == "unknown"
+}
+
+// packageProblem — описание падения пакета, не сводящегося к упавшим тестам
+// (пакет не собрался, panic, timeout, data race): пусто, если такого нет.
+func packageProblem(res *PackageResult) string {
+	switch res.Status {
+	case "pass", "skip", "flaky":
+		return ""
+	}
+	if res.Reason != "" {
+		return res.Reason
+	}
+	for _, t := range res.Tests {
+		if testFailed(t) {
+			return ""
+		}
+	}
+	return "package status: " + res.Status
+}
+
+// JUnit XML: testsuite на пакет, testcase на тест (включая подтесты).
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+func toJUnit(results map[string]*PackageResult, ignored []string) junitTestSuites {
+	all := junitTestSuites{Name: "go test"}
+	for _, pkg := range sortedPackages(results) {
+		res := results[pkg]
+		suite := junitTestSuite{Name: pkg, Time: seconds(res.Elapsed)}
+		for _, t := range res.Tests {
+			tc := junitTestCase{Name: t.Name, ClassName: pkg, Time: seconds(t.Elapsed)}
+			switch {
+			case testFailed(t):
+				tc.Failure = &junitFailure{Message: failureMessage(t), Type: t.Status, Text: t.Output}
+				suite.Failures++
+			case t.Status == "skip":
+				tc.Skipped = &junitSkipped{}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		if msg := packageProblem(res); msg != "" {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      pkg,
+				ClassName: pkg,
+				Time:      seconds(res.Elapsed),
+				Failure:   &junitFailure{Message: msg, Type: res.Status, Text: res.Details},
+			})
+			suite.Failures++
+		}
+		suite.Tests = len(suite.Cases)
+
+		all.Tests += suite.Tests
+		all.Failures += suite.Failures
+		all.Skipped += suite.Skipped
+		all.Suites = append(all.Suites, suite)
+	}
+	for _, pkg := range ignored {
+		all.Suites = append(all.Suites, junitTestSuite{
+			Name:    pkg,
+			Tests:   1,
+			Skipped: 1,
+			Time:    seconds(0),
+			Cases:   []junitTestCase{{Name: pkg, ClassName: pkg, Time: seconds(0), Skipped: &junitSkipped{Message: ignoredMessage}}},
+		})
+		all.Tests++
+		all.Skipped++
+	}
+	return all
+}
+
+func failureMessage(t *TestResult) string {
+	switch t.Status {
+	case "unknown":
+		return "no result: the test did not finish"
+	case "flaky":
+		return fmt.Sprintf("flaky: failed %d of %d runs", t.Failed, t.Runs)
+	}
+	return fmt.Sprintf("failed %d of %d runs", t.Failed, t.Runs)
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
+
+// toTAP — TAP version 13: строка на тест, подробности падения в YAML-блоке.
+func toTAP(results map[string]*PackageResult, ignored []string) []byte {
+	var body strings.Builder
+	n := 0
+	point := func(ok bool, desc, directive string, yaml []string) {
+		n++
+		status := "ok"
+		if !ok {
+			status = "not ok"
+		}
+		fmt.Fprintf(&body, "%s %d - %s%s\n", status, n, tapEscape(desc), directive)
+		if len(yaml) > 0 {
+			body.WriteString("  ---\n")
+			for _, l := range yaml {
+				body.WriteString("  " + l + "\n")
+			}
+			body.WriteString("  ...\n")
+		}
+	}
+
+	for _, pkg := range sortedPackages(results) {
+		res := results[pkg]
+		for _, t := range res.Tests {
+			desc := pkg + " " + t.Name
+			switch {
+			case testFailed(t):
+				yaml := []string{
+					"status: " + t.Status,
+					"message: " + yamlString(failureMessage(t)),
+					"duration_ms: " + fmt.Sprintf("%.0f", t.Elapsed*1000),
+				}
+				yaml = append(yaml, yamlBlock("output", t.Output)...)
+				point(false, desc, "", yaml)
+			case t.Status == "skip":
+				point(true, desc, " # SKIP", nil)
+			default:
+				point(true, desc, "", nil)
+			}
+		}
+		if msg := packageProblem(res); msg != "" {
+			yaml := []string{"status: " + res.Status, "message: " + yamlString(msg)}
+			yaml = append(yaml, yamlBlock("details", res.Details)...)
+			point(false, pkg, "", yaml)
+		}
+	}
+	for _, pkg := range ignored {
+		point(true, pkg, " # SKIP "+ignoredMessage, nil)
+	}
+
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", n)
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
+
+// tapEscape: '#' в описании TAP начинает директиву.
+func tapEscape(s string) string {
+	return strings.ReplaceAll(s, "#", `\#`)
+}
+
+// yamlBlock — многострочное значение YAML (literal block); пусто для пустого текста.
+func yamlBlock(key, text string) []string {
+	if text == "" {
+		return nil
+	}
+	out := []string{key + ": |"}
+	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		out = append(out, "  "+l)
+	}
+	return out
+}
+
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testResults() map[string]*PackageResult {
+	return map[string]*PackageResult{
+		"m/a": {Status: "pass", Elapsed: 0.7, Tests: []*TestResult{
+			{Name: "TestOK", Status: "pass", Elapsed: 0.5, Runs: 1, Passed: 1},
+			{Name: "TestSkip", Status: "skip", Runs: 1, Skipped: 1},
+		}},
+		"m/b": {Status: "fail", Tests: []*TestResult{
+			{Name: "TestBad", Status: "fail", Elapsed: 0.1, Runs: 1, Failed: 1, Output: "    x_test.go:5: got <nil>\n"},
+			{Name: "TestFlaky", Status: "flaky", Runs: 2, Passed: 1, Failed: 1},
+		}},
+		"m/c": {Status: "build_failed", Reason: "build failed: x.go:3:1: undefined: foo", Details: "# m/c\nx.go:3:1: undefined: foo\n"},
+		"m/d": {Status: "pass", Tests: []*TestResult{{Name: "TestCase#01", Status: "pass", Runs: 1, Passed: 1}}},
+	}
+}
+
+func TestToTAP(t *testing.T) {
+	t.Parallel()
+
+	got := string(toTAP(testResults(), []string{"m/cmd/tool"}))
+	want := `TAP version 13
+1..7
+ok 1 - m/a TestOK
+ok 2 - m/a TestSkip # SKIP
+not ok 3 - m/b TestBad
+  ---
+  status: fail
+  message: "failed 1 of 1 runs"
+  duration_ms: 100
+  output: |
+        x_test.go:5: got <nil>
+  ...
+not ok 4 - m/b TestFlaky
+  ---
+  status: flaky
+  message: "flaky: failed 1 of 2 runs"
+  duration_ms: 0
+  ...
+not ok 5 - m/c
+  ---
+  status: build_failed
+  message: "build failed: x.go:3:1: undefined: foo"
+  details: |
+    # m/c
+    x.go:3:1: undefined: foo
+  ...
+ok 6 - m/d TestCase\#01
+ok 7 - m/cmd/tool # SKIP ignored by tests.ignore_packages
+`
+	if got != want {
+		t.Errorf("toTAP() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestToJUnit(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "report.xml")
+	if err := writeReport(p, "junit", testResults(), []string{"m/cmd/tool"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(b, &got); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, b)
+	}
+	if got.Tests != 7 || got.Failures != 3 || got.Skipped != 2 {
+		t.Errorf("testsuites tests=%d failures=%d skipped=%d; want 7, 3, 2", got.Tests, got.Failures, got.Skipped)
+	}
+
+	type suite struct {
+		name                     string
+		tests, failures, skipped int
+	}
+	var suites []suite
+	failures := map[string]string{}
+	for _, s := range got.Suites {
+		suites = append(suites, suite{s.Name, s.Tests, s.Failures, s.Skipped})
+		if s.Tests != len(s.Cases) {
+			t.Errorf("suite %s: tests=%d, but %d testcases", s.Name, s.Tests, len(s.Cases))
+		}
+		for _, tc := range s.Cases {
+			if tc.Failure != nil {
+				failures[tc.ClassName+" "+tc.Name] = tc.Failure.Type + ": " + tc.Failure.Message + ": " + tc.Failure.Text
+			}
+		}
+	}
+	wantSuites := []suite{
+		{"m/a", 2, 0, 1},
+		{"m/b", 2, 2, 0},
+		{"m/c", 1, 1, 0},
+		{"m/d", 1, 0, 0},
+		{"m/cmd/tool", 1, 0, 1},
+	}
+	if !reflect.DeepEqual(suites, wantSuites) {
+		t.Errorf("suites =\n%+v\nwant\n%+v", suites, wantSuites)
+	}
+	wantFailures := map[string]string{
+		"m/b TestBad":   "fail: failed 1 of 1 runs:     x_test.go:5: got <nil>\n",
+		"m/b TestFlaky": "flaky: flaky: failed 1 of 2 runs: ",
+		"m/c m/c":       "build_failed: build failed: x.go:3:1: undefined: foo: # m/c\nx.go:3:1: undefined: foo\n",
+	}
+	if !reflect.DeepEqual(failures, wantFailures) {
+		t.Errorf("failures =\n%q\nwant\n%q", failures, wantFailures)
+	}
+}
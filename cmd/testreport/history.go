@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// HistoryRecord — одна строка файла истории (JSONL, только дописывается).
+type HistoryRecord struct {
+	SHA      string                    `json:"sha,omitempty"`
+	Time     string                    `json:"time"`
+	Packages map[string]HistoryPackage `json:"packages"`
+}
+
+type HistoryPackage struct {
+	Status  string                 `json:"status"`
+	Elapsed float64                `json:"elapsed,omitempty"`
+	Tests   map[string]HistoryTest `json:"tests,omitempty"`
+}
+
+type HistoryTest struct {
+	Status  string  `json:"status"`
+	Elapsed float64 `json:"elapsed,omitempty"`
+}
+
+// appendHistory дописывает прогон в конец файла истории.
+func appendHistory(p, sha string, results map[string]*PackageResult) error {
+	rec := HistoryRecord{
+		SHA:      sha,
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		Packages: make(map[string]HistoryPackage, len(results)),
+	}
+	for pkg, res := range results {
+		hp := HistoryPackage{Status: res.Status, Elapsed: res.Elapsed}
+		if len(res.Tests) > 0 {
+			hp.Tests = make(map[string]HistoryTest, len(res.Tests))
+			for _, t := range res.Tests {
+				hp.Tests[t.Name] = HistoryTest{Status: t.Status, Elapsed: t.Elapsed}
+			}
+		}
+		rec.Packages[pkg] = hp
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	// прошлая запись оборвалась без \n — начинаем с новой строки, иначе
+	// readHistory потеряет и её, и эту
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			b = append([]byte{'\n'}, b...)
+		}
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readHistory(p string) ([]HistoryRecord, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []HistoryRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1024), 64*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var rec HistoryRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			// оборванная при записи строка не должна ломать всю историю
+			fmt.Fprintf(os.Stderr, "WARN: %s:%d: %v\n", p, n, err)
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+// trend — статистика одного пакета или теста по истории.
+type trend struct {
+	name       string
+	runs       int
+	passed     int
+	durations  []float64
+	lastStatus string
+	change     string // когда и как статус менялся в последний раз
+}
+
+func (t *trend) add(rec HistoryRecord, status string, elapsed float64) {
+	t.runs++
+	if status == "pass" {
+		t.passed++
+	}
+	t.durations = append(t.durations, elapsed)
+	if t.lastStatus != "" && status != t.lastStatus {
+		t.change = fmt.Sprintf("%s → %s at %s %s", t.lastStatus, status, shortSHA(rec.SHA), rec.Time)
+	}
+	t.lastStatus = status
+}
+
+// runHistory: testreport history — сводка по файлу истории.
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	histPath := fs.String("file", "test-history.jsonl", "history file written with -history")
+	pkgPattern := fs.String("package", "", "only packages matching this path.Match pattern")
+	withTests := fs.Bool("tests", false, "also show per-test trends")
+	last := fs.Int("last", 0, "only the last N runs (0: all)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	recs, err := readHistory(*histPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read history: %v\n", err)
+		return 2
+	}
+	if *last > 0 && len(recs) > *last {
+		recs = recs[len(recs)-*last:]
+	}
+	if len(recs) == 0 {
+		fmt.Println("history is empty")
+		return 0
+	}
+
+	trends := map[string]*trend{}
+	get := func(name string) *trend {
+		t, ok := trends[name]
+		if !ok {
+			t = &trend{name: name}
+			trends[name] = t
+		}
+		return t
+	}
+	for _, rec := range recs {
+		for pkg, hp := range rec.Packages {
+			if *pkgPattern != "" {
+				if ok, _ := path.Match(*pkgPattern, pkg); !ok {
+					continue
+				}
+			}
+			get(pkg).add(rec, hp.Status, hp.Elapsed)
+			if *withTests {
+				for name, ht := range hp.Tests {
+					get(pkg+" "+name).add(rec, ht.Status, ht.Elapsed)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(trends))
+	for name := range trends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%d runs, %s … %s\n", len(recs), recs[0].Time, recs[len(recs)-1].Time)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tRUNS\tPASS RATE\tMEDIAN\tLAST\tLAST CHANGE")
+	for _, name := range names {
+		t := trends[name]
+		change := t.change
+		if change == "" {
+			change = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.0f%%\t%.2fs\t%s\t%s\n",
+			name, t.runs, 100*float64(t.passed)/float64(t.runs), median(t.durations), t.lastStatus, change)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "write: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	if n := len(s); n%2 == 0 {
+		return (s[n/2-1] + s[n/2]) / 2
+	}
+	return s[len(s)/2]
+}
+
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "?"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
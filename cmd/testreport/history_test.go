@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "history.jsonl")
+	runs := []map[string]*PackageResult{
+		{"m/a": {Status: "pass", Elapsed: 1.5, Tests: []*TestResult{{Name: "TestA", Status: "pass", Elapsed: 0.5}}}},
+		{"m/a": {Status: "fail", Elapsed: 2}, "m/b": {Status: "skip"}},
+	}
+	if err := appendHistory(p, "sha1", runs[0]); err != nil {
+		t.Fatal(err)
+	}
+	// оборванная запись (без \n) не должна терять соседние строки
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{\"sha\":\"broken\",\"pack"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := appendHistory(p, "", runs[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := readHistory(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("readHistory() = %d records; want 2", len(recs))
+	}
+	for i, rec := range recs {
+		if rec.Time == "" {
+			t.Errorf("record %d: empty time", i)
+		}
+	}
+	want := []map[string]HistoryPackage{
+		{"m/a": {Status: "pass", Elapsed: 1.5, Tests: map[string]HistoryTest{"TestA": {Status: "pass", Elapsed: 0.5}}}},
+		{"m/a": {Status: "fail", Elapsed: 2}, "m/b": {Status: "skip"}},
+	}
+	if recs[0].SHA != "sha1" || recs[1].SHA != "" {
+		t.Errorf("SHAs = %q, %q; want sha1, empty", recs[0].SHA, recs[1].SHA)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(recs[i].Packages, want[i]) {
+			t.Errorf("record %d packages =\n%+v\nwant\n%+v", i, recs[i].Packages, want[i])
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   []float64
+		want float64
+	}{
+		{nil, 0},
+		{[]float64{3}, 3},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		in := append([]float64(nil), tt.in...)
+		if got := median(tt.in); got != tt.want {
+			t.Errorf("median(%v) = %g; want %g", tt.in, got, tt.want)
+		}
+		if !reflect.DeepEqual(tt.in, in) {
+			t.Errorf("median(%v) reordered its input", in)
+		}
+	}
+}
+
+func TestTrendLastChange(t *testing.T) {
+	t.Parallel()
+
+	runs := []struct {
+		sha, status string
+	}{
+		{"aaaaaaaaaa", "pass"},
+		{"bbbbbbbbbb", "pass"},
+		{"cccccccccc", "fail"},
+		{"dddddddddd", "fail"},
+		{"", "pass"},
+		{"ffffffffff", "pass"},
+	}
+	tr := &trend{name: "m/a"}
+	for i, r := range runs {
+		tr.add(HistoryRecord{SHA: r.sha, Time: "T" + string(rune('1'+i))}, r.status, float64(i))
+	}
+	if tr.runs != 6 || tr.passed != 4 || tr.lastStatus != "pass" {
+		t.Errorf("runs, passed, last = %d, %d, %q; want 6, 4, pass", tr.runs, tr.passed, tr.lastStatus)
+	}
+	if want := "fail → pass at ? T5"; tr.change != want {
+		t.Errorf("change = %q; want %q", tr.change, want)
+	}
+	if got := median(tr.durations); got != 2.5 {
+		t.Errorf("median(durations) = %g; want 2.5", got)
+	}
+}
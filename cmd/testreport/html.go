@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"industry_backend_go/internal/gomod"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var reportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"seconds": seconds,
+	"deref":   func(f *float64) float64 { return *f },
+}).Parse(reportTemplate))
+
+type htmlReport struct {
+	Generated string
+	Score     float64
+	MaxScore  float64
+	Counts    []statusCount
+	Packages  []htmlPackage
+	Ignored   []string
+}
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+type htmlPackage struct {
+	Name   string
+	Readme string
+	*PackageResult
+}
+
+// toHTML — самодостаточная страница: стили внутри, без внешних ресурсов.
+// readmeBase — префикс ссылок на README задач (пусто: относительные пути).
+func toHTML(results map[string]*PackageResult, ignored []string, readmeBase string) ([]byte, error) {
+	rep := htmlReport{Generated: time.Now().UTC().Format(time.RFC3339), Ignored: ignored}
+	module := gomod.ModulePath("go.mod")
+
+	counts := map[string]int{}
+	for _, pkg := range sortedPackages(results) {
+		res := results[pkg]
+		counts[res.Status]++
+		if res.Score != nil {
+			rep.Score += *res.Score
+			rep.MaxScore += res.MaxScore
+		}
+		rep.Packages = append(rep.Packages, htmlPackage{
+			Name:          pkg,
+			Readme:        readmeLink(module, pkg, readmeBase),
+			PackageResult: res,
+		})
+	}
+	rep.Score, rep.MaxScore = round2(rep.Score), round2(rep.MaxScore)
+	for st, n := range counts {
+		rep.Counts = append(rep.Counts, statusCount{Status: st, Count: n})
+	}
+	sort.Slice(rep.Counts, func(i, j int) bool { return rep.Counts[i].Status < rep.Counts[j].Status })
+
+	var b bytes.Buffer
+	if err := reportTmpl.Execute(&b, rep); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// readmeLink — ссылка на README.md пакета, если он есть в рабочем дереве.
+func readmeLink(module, pkg, base string) string {
+	rel, ok := strings.CutPrefix(pkg, module+"/")
+	if module == "" || !ok {
+		return ""
+	}
+	readme := path.Join(rel, "README.md")
+	if _, err := os.Stat(filepath.FromSlash(readme)); err != nil {
+		return ""
+	}
+	if base == "" {
+		return readme
+	}
+	return strings.TrimSuffix(base, "/") + "/" + readme
+}
+
+// defaultReadmeBase — ссылка на файлы коммита в GitHub, если запущены в Actions.
+func defaultReadmeBase() string {
+	server, repo, sha := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_SHA")
+	if server == "" || repo == "" || sha == "" {
+		return ""
+	}
+	return server + "/" + repo + "/blob/" + sha
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	t.Parallel()
+
+	score := 7.5
+	results := map[string]*PackageResult{
+		"m/tasks/task_01": {Status: "pass", Elapsed: 1.25, Score: &score, MaxScore: 10, Tests: []*TestResult{
+			{Name: "TestOK", Status: "pass", Elapsed: 0.5, Runs: 1, Passed: 1},
+			{Name: "TestFlaky", Status: "flaky", Runs: 3, Passed: 2, Failed: 1, Output: "    x_test.go:9: got <nil> & \"1\"\n"},
+		}},
+		"m/tasks/task_02": {Status: "panic", Reason: "panic: <script>alert(1)</script>", Details: "goroutine 1 [running]:\n<b>main</b>"},
+	}
+
+	b, err := toHTML(results, []string{"m/cmd/<tool>"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+
+	for _, want := range []string{
+		`<section id="m/tasks/task_01">`,
+		`score 7.5 / 10`,
+		`<span class="pill panic">panic: 1</span><span class="pill pass">pass: 1</span>`,
+		`<td>TestOK</td>`,
+		`1 of 3 failed`,
+		`x_test.go:9: got &lt;nil&gt; &amp; &#34;1&#34;`,
+		`<div class="reason">panic: &lt;script&gt;alert(1)&lt;/script&gt;</div>`,
+		`<pre>goroutine 1 [running]:` + "\n" + `&lt;b&gt;main&lt;/b&gt;</pre>`,
+		`<li><code>m/cmd/&lt;tool&gt;</code></li>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("toHTML() does not contain %q", want)
+		}
+	}
+	for _, bad := range []string{"<script>", "<b>main", "<tool>", "<nil>"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("toHTML() contains unescaped %q", bad)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ignoreMatcher — пакеты из tests.ignore_packages. Шаблон — точный путь,
+// glob (path.Match) или префикс с "/..." как у go list: "mod/cmd/..."
+// совпадает с mod/cmd и всеми пакетами под ним.
+type ignoreMatcher struct {
+	patterns []string
+	seen     map[string]struct{} // пропущенные пакеты, для сводки
+}
+
+func newIgnoreMatcher(patterns []string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{seen: map[string]struct{}{}}
+	for i, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(strings.TrimSuffix(p, "/..."), ""); err != nil {
+			return nil, fmt.Errorf("tests.ignore_packages[%d]: bad pattern %q: %w", i, p, err)
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// match сообщает, игнорируется ли пакет, и запоминает его для сводки.
+func (m *ignoreMatcher) match(pkg string) bool {
+	for _, p := range m.patterns {
+		if matchPackage(p, pkg) {
+			m.seen[pkg] = struct{}{}
+			return true
+		}
+	}
+	return false
+}
+
+func matchPackage(pattern, pkg string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+			return true
+		}
+		// glob в префиксе: сравниваем столько же сегментов пути
+		n := strings.Count(prefix, "/") + 1
+		parts := strings.SplitN(pkg, "/", n+1)
+		if len(parts) < n {
+			return false
+		}
+		ok, _ := path.Match(prefix, strings.Join(parts[:n], "/"))
+		return ok
+	}
+	ok, _ := path.Match(pattern, pkg)
+	return ok
+}
+
+// ignored — пропущенные пакеты, встретившиеся во входе или в -pkgs.
+func (m *ignoreMatcher) ignored() []string {
+	out := make([]string, 0, len(m.seen))
+	for pkg := range m.seen {
+		out = append(out, pkg)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PackagesSummary — файл -packages-out: пакеты, которых нет в отчёте
+// (ignored) или которые в нём есть, но не запускались (missing).
+type PackagesSummary struct {
+	Ignored []string `json:"ignored"`
+	Missing []string `json:"missing"`
+}
+
+func writePackages(p string, ignored, missing []string) error {
+	sum := PackagesSummary{Ignored: ignored, Missing: missing}
+	if sum.Missing == nil {
+		sum.Missing = []string{}
+	}
+	b, err := json.MarshalIndent(sum, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchPackage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, pkg string
+		want         bool
+	}{
+		{"m/cmd/tool", "m/cmd/tool", true},
+		{"m/cmd/tool", "m/cmd/tool2", false},
+		{"m/cmd/...", "m/cmd", true},
+		{"m/cmd/...", "m/cmd/tool", true},
+		{"m/cmd/...", "m/cmd/tool/sub", true},
+		{"m/cmd/...", "m/cmdx/tool", false},
+		{"m/tasks/task_0*", "m/tasks/task_01", true},
+		{"m/tasks/task_0*", "m/tasks/task_01/sub", false},
+		{"m/*/...", "m/internal/config", true},
+		{"m/*/...", "m", false},
+		{"m/i*/...", "m/cmd/tool", false},
+	}
+	for _, tt := range tests {
+		if got := matchPackage(tt.pattern, tt.pkg); got != tt.want {
+			t.Errorf("matchPackage(%q, %q) = %v; want %v", tt.pattern, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestCollectorIgnoredAndMissing(t *testing.T) {
+	t.Parallel()
+
+	ignored, err := newIgnoreMatcher([]string{"m/cmd/..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := newCollector(ignored, 8192)
+	expected := []string{"m/tasks/a", "m/tasks/b", "m/cmd/tool"}
+	for _, p := range expected {
+		col.ensure(p)
+	}
+	events := `{"Action":"pass","Package":"m/tasks/a"}
+{"Action":"fail","Package":"m/cmd/tool"}`
+	if err := scanEvents(strings.NewReader(events), col.add); err != nil {
+		t.Fatal(err)
+	}
+	col.finish()
+
+	if got := col.missing(expected); !reflect.DeepEqual(got, []string{"m/tasks/b"}) {
+		t.Errorf("missing() = %q; want [m/tasks/b]", got)
+	}
+	if _, ok := col.results["m/cmd/tool"]; ok {
+		t.Error("ignored package is in results")
+	}
+	if got := ignored.ignored(); !reflect.DeepEqual(got, []string{"m/cmd/tool"}) {
+		t.Errorf("ignored() = %q; want [m/cmd/tool]", got)
+	}
+}
@@ -6,21 +6,34 @@ import (
 	"flag"
 	"fmt"
 	"industry_backend_go/internal/config"
+	"io"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 )
 
 type TestEvent struct {
-	Action  string  `json:"Action"`
-	Package string  `json:"Package"`
-	Test    string  `json:"Test,omitempty"`
-	Output  string  `json:"Output,omitempty"`
-	Elapsed float64 `json:"Elapsed,omitempty"`
+	Action      string  `json:"Action"`
+	Package     string  `json:"Package"`
+	Test        string  `json:"Test,omitempty"`
+	Output      string  `json:"Output,omitempty"`
+	Elapsed     float64 `json:"Elapsed,omitempty"`
+	ImportPath  string  `json:"ImportPath,omitempty"`  // build-output / build-fail
+	FailedBuild string  `json:"FailedBuild,omitempty"` // пакет не собрался
 }
 
 type PackageResult struct {
-	Status      string   `json:"status"` // pass|fail|skip|unknown
-	FailedTests []string `json:"failed_tests,omitempty"`
+	Status      string             `json:"status"` // pass|fail|flaky|skip|unknown|build_failed|panic|timeout|data_race|slow
+	Reason      string             `json:"reason,omitempty"`
+	Details     string             `json:"details,omitempty"` // сообщения компилятора, начало стека, отчёт race
+	Elapsed     float64            `json:"elapsed,omitempty"`
+	Score       *float64           `json:"score,omitempty"` // только для пакетов из scoring
+	MaxScore    float64            `json:"max_score,omitempty"`
+	Coverage    *Coverage          `json:"coverage,omitempty"`
+	Benchmarks  []*BenchmarkResult `json:"benchmarks,omitempty"`
+	FailedTests []string           `json:"failed_tests,omitempty"`
+	Tests       []*TestResult      `json:"tests,omitempty"`
 }
 
 func loadPackages(path string) ([]string, error) {
@@ -42,7 +55,7 @@ func loadPackages(path string) ([]string, error) {
 	return pkgs, nil
 }
 
-func ignoredPackage(configPath *string) map[string]struct{} {
+func loadConfig(configPath *string) config.Config {
 	if configPath == nil {
 		fmt.Fprintf(os.Stderr, "non parse config path\n")
 		os.Exit(2)
@@ -52,49 +65,92 @@ func ignoredPackage(configPath *string) map[string]struct{} {
 		fmt.Fprintf(os.Stderr, "create cfg: %v\n", err)
 		os.Exit(2)
 	}
-	ignoredPkgsLines := cfg.Tests.IgnorePackages
-	ignoredPkgs := make(map[string]struct{}, len(ignoredPkgsLines))
-	for _, l := range ignoredPkgsLines {
-		l = strings.TrimSpace(l)
-		if l != "" {
-			ignoredPkgs[l] = struct{}{}
+	return cfg
+}
+
+// scanEvents читает go test -json построчно; строки не в JSON пропускаются.
+func scanEvents(r io.Reader, fn func(TestEvent)) error {
+	sc := bufio.NewScanner(r)
+	// go test output lines can be large (panic stacktrace, long logs)
+	sc.Buffer(make([]byte, 1024), 10*1024*1024)
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || !strings.HasPrefix(line, "{") {
+			continue
 		}
+
+		var ev TestEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			// ignore non-json garbage lines
+			continue
+		}
+		fn(ev)
 	}
-	return ignoredPkgs
+	return sc.Err()
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		os.Exit(runHistory(os.Args[2:]))
+	}
+
 	inPath := flag.String("in", "", "input file (go test -json output). If empty: read stdin")
-	outPath := flag.String("out", "package-results.json", "output json file")
+	outPath := flag.String("out", "package-results.json", "output file (with several formats the extension is replaced per format)")
+	formatList := flag.String("format", "json", "output formats, comma separated: json, junit, tap, html")
 	pkgsPath := flag.String("pkgs", "", "optional packages list file (one package per line), e.g. from `go list ./...`")
 	configPath := flag.String("config", "./.etc/config.json", "config file")
+	scoresPath := flag.String("scores", "", "optional output file with per-task and total scores (config scoring section)")
+	benchPath := flag.String("bench", "", "optional go test -bench -benchmem -json output of a separate benchmark run")
+	benchProcs := flag.Int("bench-procs", runtime.GOMAXPROCS(0), "GOMAXPROCS of the benchmark run: only this -N suffix is stripped from benchmark names")
+	coverPath := flag.String("cover", "", "optional go test -coverprofile file: statement coverage per package and per function of solution.go")
+	readmeBase := flag.String("readme-base", defaultReadmeBase(), "URL prefix for task README links in the html report (empty: relative links)")
+	historyPath := flag.String("history", "", "append this run to a JSONL history file (see testreport history)")
+	sha := flag.String("sha", os.Getenv("GITHUB_SHA"), "commit SHA recorded in -history")
+	follow := flag.Bool("follow", false, "print live progress to stderr while reading (e.g. go test -json ./... | testreport -follow)")
+	packagesPath := flag.String("packages-out", "", "optional output file listing ignored (tests.ignore_packages) and missing (-pkgs without events) packages")
+	requireAll := flag.Bool("require-all", false, "exit 1 if an expected package from -pkgs produced no test events")
+	maxOutput := flag.Int("max-output", 8192, "max captured output bytes per failed test (0: don't capture)")
 	flag.Parse()
 
+	formats, err := reportFormats.Parse(*formatList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse formats: %v\n", err)
+		os.Exit(2)
+	}
+
 	pkgs, err := loadPackages(*pkgsPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "load pkgs: %v\n", err)
 		os.Exit(2)
 	}
 
-	ignoredPkgs := ignoredPackage(configPath)
-	_ = ignoredPkgs
+	cfg := loadConfig(configPath)
+	scoring, err := compileScoring(cfg.Scoring)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create cfg: %v\n", err)
+		os.Exit(2)
+	}
 
-	results := map[string]*PackageResult{}
-	ensure := func(pkg string) {
-		if pkg == "" {
-			return
-		}
-		if _, ok := ignoredPkgs[pkg]; ok {
-			return
-		}
-		if _, ok := results[pkg]; !ok {
-			results[pkg] = &PackageResult{Status: "unknown"}
-		}
+	benchLimits, err := compileBenchLimits(cfg.Tests.Benchmarks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create cfg: %v\n", err)
+		os.Exit(2)
+	}
+
+	ignored, err := newIgnoreMatcher(cfg.Tests.IgnorePackages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create cfg: %v\n", err)
+		os.Exit(2)
 	}
+	col := newCollector(ignored, *maxOutput)
 
 	// prefill expected packages (so they appear even if no events were emitted)
 	for _, p := range pkgs {
-		ensure(p)
+		col.ensure(p)
 	}
 
 	var in *os.File
@@ -110,67 +166,105 @@ func main() {
 		in = f
 	}
 
-	sc := bufio.NewScanner(in)
-	// go test output lines can be large (panic stacktrace, long logs)
-	sc.Buffer(make([]byte, 1024), 10*1024*1024)
+	var prog *progress
+	stopProgress := func() {}
+	if *follow {
+		prog = newProgress(os.Stderr)
+		stop, done := make(chan struct{}), make(chan struct{})
+		go prog.loop(time.Second, stop, done)
+		stopProgress = func() {
+			close(stop)
+			<-done
+		}
+	}
 
-	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		if line == "" || !strings.HasPrefix(line, "{") {
-			continue
+	bench := newBenchCollector(*benchProcs)
+	err = scanEvents(in, func(ev TestEvent) {
+		col.add(ev)
+		bench.add(ev)
+		if prog != nil {
+			prog.event(ev)
 		}
+	})
+	stopProgress()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan input: %v\n", err)
+		os.Exit(2)
+	}
+	col.finish()
 
-		var ev TestEvent
-		if err := json.Unmarshal([]byte(line), &ev); err != nil {
-			// ignore non-json garbage lines
-			continue
+	missing := col.missing(pkgs)
+	for _, pkg := range missing {
+		col.results[pkg].Reason = "no test events: package was not run"
+		fmt.Fprintf(os.Stderr, "WARN: expected package %s produced no test events\n", pkg)
+	}
+	if skipped := ignored.ignored(); len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "ignored %d packages (tests.ignore_packages):\n", len(skipped))
+		for _, pkg := range skipped {
+			fmt.Fprintf(os.Stderr, "  %s\n", pkg)
 		}
+	}
 
-		if ev.Package == "" {
-			continue
+	if *benchPath != "" {
+		// из отдельного прогона бенчмарков берём только их результаты: статусы
+		// пакетов там свои (обычно -run '^$') и не должны перетирать тестовые
+		f, err := os.Open(*benchPath)
+		if err == nil {
+			err = scanEvents(f, bench.add)
+			f.Close()
 		}
-		ensure(ev.Package)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read benchmarks: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	applyBenchmarks(bench, benchLimits, col.results)
 
-		// package-level result: Action pass/fail/skip and empty Test
-		if ev.Test == "" {
-			if _, ignored := ignoredPkgs[ev.Package]; ignored {
-				continue
-			}
-			switch ev.Action {
-			case "pass":
-				results[ev.Package].Status = "pass"
-			case "fail":
-				results[ev.Package].Status = "fail"
-			case "skip":
-				// sometimes packages get skipped; keep it explicit
-				if results[ev.Package].Status == "unknown" {
-					results[ev.Package].Status = "skip"
-				}
+	if *coverPath != "" {
+		blocks, err := readCoverProfile(*coverPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read coverage: %v\n", err)
+			os.Exit(2)
+		}
+		applyCoverage(blocks, col.results)
+	}
+
+	if len(scoring) > 0 {
+		sum := applyScoring(scoring, col.results)
+		if len(sum.Tasks) > 0 {
+			fmt.Printf("score: %g/%g\n", sum.Total, sum.MaxTotal)
+		}
+		if *scoresPath != "" {
+			if err := writeScores(*scoresPath, sum); err != nil {
+				fmt.Fprintf(os.Stderr, "write scores: %v\n", err)
+				os.Exit(2)
 			}
-			continue
 		}
+	}
 
-		// test-level fail: Action fail and Test present
-		if ev.Action == "fail" && ev.Test != "" {
-			results[ev.Package].FailedTests = append(results[ev.Package].FailedTests, ev.Test)
+	if *packagesPath != "" {
+		if err := writePackages(*packagesPath, ignored.ignored(), missing); err != nil {
+			fmt.Fprintf(os.Stderr, "write packages: %v\n", err)
+			os.Exit(2)
 		}
 	}
-	if err := sc.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "scan input: %v\n", err)
-		os.Exit(2)
+
+	for _, f := range formats {
+		if err := writeReport(reportFormats.OutputPath(*outPath, f, len(formats) > 1), f, col.results, ignored.ignored(), *readmeBase); err != nil {
+			fmt.Fprintf(os.Stderr, "write output: %v\n", err)
+			os.Exit(2)
+		}
 	}
 
-	out, err := os.Create(*outPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "create output: %v\n", err)
-		os.Exit(2)
+	if *historyPath != "" {
+		if err := appendHistory(*historyPath, *sha, col.results); err != nil {
+			fmt.Fprintf(os.Stderr, "write history: %v\n", err)
+			os.Exit(2)
+		}
 	}
-	defer out.Close()
 
-	enc := json.NewEncoder(out)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(results); err != nil {
-		fmt.Fprintf(os.Stderr, "write output: %v\n", err)
-		os.Exit(2)
+	if *requireAll && len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "%d expected packages produced no test events\n", len(missing))
+		os.Exit(1)
 	}
 }
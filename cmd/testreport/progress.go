@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progress — живой статус для -follow: пишется в stderr, пока идёт go test.
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	tty     bool
+	start   time.Time
+	running map[string]string // package → текущий тест
+	passed  int
+	failed  int
+	skipped int
+	last    string // последняя напечатанная строка статуса
+}
+
+func newProgress(w *os.File) *progress {
+	tty := false
+	if fi, err := w.Stat(); err == nil {
+		tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	return &progress{w: w, tty: tty, start: time.Now(), running: map[string]string{}}
+}
+
+func (p *progress) event(ev TestEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ev.Package == "" {
+		return
+	}
+	if ev.Test != "" {
+		switch ev.Action {
+		case "run":
+			p.running[ev.Package] = ev.Test
+		case "fail":
+			p.printLine(fmt.Sprintf("--- FAIL %s %s (%.2fs)", ev.Package, ev.Test, ev.Elapsed))
+		}
+		return
+	}
+
+	switch ev.Action {
+	case "start":
+		p.running[ev.Package] = ""
+	case "pass":
+		delete(p.running, ev.Package)
+		p.passed++
+	case "skip":
+		delete(p.running, ev.Package)
+		p.skipped++
+	case "fail":
+		delete(p.running, ev.Package)
+		p.failed++
+		p.printLine(fmt.Sprintf("FAIL %s (%.2fs)", ev.Package, ev.Elapsed))
+	}
+}
+
+// status — компактная строка: счётчики пакетов и текущий тест.
+func (p *progress) status() string {
+	s := fmt.Sprintf("[%s] running %d, passed %d, failed %d",
+		time.Since(p.start).Round(time.Second), len(p.running), p.passed, p.failed)
+	if p.skipped > 0 {
+		s += fmt.Sprintf(", skipped %d", p.skipped)
+	}
+
+	pkgs := make([]string, 0, len(p.running))
+	for pkg := range p.running {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		if test := p.running[pkg]; test != "" {
+			s += " | " + pkg + " " + test
+			break
+		}
+	}
+	return s
+}
+
+// printLine печатает событие над строкой статуса.
+func (p *progress) printLine(s string) {
+	if p.tty {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+	fmt.Fprintln(p.w, s)
+}
+
+// tick перерисовывает статус; без терминала печатает его, только если он
+// изменился, чтобы не засорять лог CI.
+func (p *progress) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.status()
+	if p.tty {
+		fmt.Fprint(p.w, "\r\033[K"+s)
+		return
+	}
+	// время в начале строки меняется всегда — сравниваем без него
+	_, key, _ := strings.Cut(s, "] ")
+	if key != p.last {
+		fmt.Fprintln(p.w, s)
+		p.last = key
+	}
+}
+
+// loop перерисовывает статус раз в interval, пока не закрыт stop.
+func (p *progress) loop(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			p.tick()
+		case <-stop:
+			p.mu.Lock()
+			if p.tty {
+				fmt.Fprint(p.w, "\r\033[K")
+			}
+			fmt.Fprintln(p.w, p.status())
+			p.mu.Unlock()
+			return
+		}
+	}
+}
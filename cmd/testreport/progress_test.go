@@ -0,0 +1,50 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressTickNoTTY(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	p := &progress{w: &out, start: time.Now(), running: map[string]string{}}
+	steps := []struct {
+		ev   *TestEvent
+		tick bool
+	}{
+		{tick: true},
+		{tick: true}, // ничего не изменилось
+		{ev: &TestEvent{Action: "start", Package: "m/a"}, tick: true},
+		{ev: &TestEvent{Action: "run", Package: "m/a", Test: "TestX"}, tick: true},
+		{tick: true},
+		{ev: &TestEvent{Action: "fail", Package: "m/a", Test: "TestX", Elapsed: 0.5}, tick: true}, // счётчики те же
+		{ev: &TestEvent{Action: "output", Package: "m/a", Test: "TestX", Output: "boom\n"}, tick: true},
+		{ev: &TestEvent{Action: "fail", Package: "m/a", Elapsed: 1}, tick: true},
+		{tick: true},
+	}
+	for _, s := range steps {
+		if s.ev != nil {
+			p.event(*s.ev)
+		}
+		if s.tick {
+			p.tick()
+		}
+	}
+
+	// время работы в начале строки статуса заменяем на [t]
+	got := regexp.MustCompile(`(?m)^\[[^]]*\] `).ReplaceAllString(out.String(), "[t] ")
+	want := `[t] running 0, passed 0, failed 0
+[t] running 1, passed 0, failed 0
+[t] running 1, passed 0, failed 0 | m/a TestX
+--- FAIL m/a TestX (0.50s)
+FAIL m/a (1.00s)
+[t] running 0, passed 0, failed 1
+`
+	if got != want {
+		t.Errorf("progress output =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"industry_backend_go/internal/config"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type TaskScoreResult struct {
+	Score    float64 `json:"score"`
+	MaxScore float64 `json:"max_score"`
+}
+
+// ScoreSummary — итог по всем задачам (файл -scores).
+type ScoreSummary struct {
+	Total    float64                    `json:"total"`
+	MaxTotal float64                    `json:"max_total"`
+	Tasks    map[string]TaskScoreResult `json:"tasks"`
+}
+
+type testRule struct {
+	re     *regexp.Regexp
+	points float64
+}
+
+type scoreRule struct {
+	cfg   config.TaskScore
+	tests []testRule
+}
+
+func compileScoring(sc config.Scoring) ([]scoreRule, error) {
+	rules := make([]scoreRule, 0, len(sc.Tasks))
+	for i, t := range sc.Tasks {
+		if _, err := path.Match(t.Package, ""); err != nil {
+			return nil, fmt.Errorf("scoring.tasks[%d]: bad package pattern %q: %w", i, t.Package, err)
+		}
+		r := scoreRule{cfg: t}
+		for j, ts := range t.Tests {
+			re, err := regexp.Compile(ts.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("scoring.tasks[%d].tests[%d]: %w", i, j, err)
+			}
+			r.tests = append(r.tests, testRule{re: re, points: ts.Points})
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// applyScoring проставляет score/max_score пакетам и возвращает итог.
+func applyScoring(rules []scoreRule, results map[string]*PackageResult) ScoreSummary {
+	sum := ScoreSummary{Tasks: map[string]TaskScoreResult{}}
+	for pkg, res := range results {
+		var rule *scoreRule
+		for i := range rules {
+			// как в правилах diff: побеждает последнее совпадение
+			if ok, _ := path.Match(rules[i].cfg.Package, pkg); ok {
+				rule = &rules[i]
+			}
+		}
+		if rule == nil {
+			continue
+		}
+
+		score, maxScore := scorePackage(*rule, res)
+		res.Score, res.MaxScore = &score, maxScore
+		sum.Tasks[pkg] = TaskScoreResult{Score: score, MaxScore: maxScore}
+		sum.Total += score
+		sum.MaxTotal += maxScore
+	}
+	sum.Total = round2(sum.Total)
+	sum.MaxTotal = round2(sum.MaxTotal)
+	return sum
+}
+
+// incompleteStatuses — пакет не дошёл до конца: после panic или timeout
+// остальные тесты не запускались, и список тестов неполон.
+var incompleteStatuses = map[string]bool{
+	"build_failed": true,
+	"panic":        true,
+	"timeout":      true,
+	"unknown":      true,
+}
+
+// scorePackage: баллы — за правильность. slow (тесты прошли, но нарушен порог
+// бенчмарка) получает полные баллы, как pass: за скорость отвечает отдельная
+// проверка в CI, а не оценка. Незавершённый пакет (incompleteStatuses) получает
+// 0: доля «прошедших из запущенных» там завышена, ни один тест после падения
+// не запускался.
+func scorePackage(rule scoreRule, res *PackageResult) (score, maxScore float64) {
+	maxScore = rule.cfg.Points
+	for _, tr := range rule.tests {
+		maxScore += tr.points
+	}
+	if incompleteStatuses[res.Status] {
+		return 0, round2(maxScore)
+	}
+
+	switch {
+	case res.Status == "pass" || res.Status == "slow":
+		score = rule.cfg.Points
+	case rule.cfg.Partial:
+		// доля прошедших тестов верхнего уровня
+		total, passed := 0, 0
+		for _, t := range res.Tests {
+			if strings.Contains(t.Name, "/") {
+				continue
+			}
+			total++
+			if t.Status == "pass" {
+				passed++
+			}
+		}
+		if total > 0 {
+			score = rule.cfg.Points * float64(passed) / float64(total)
+		}
+	}
+
+	for _, tr := range rule.tests {
+		matched, passed := 0, 0
+		for _, t := range res.Tests {
+			if !tr.re.MatchString(t.Name) {
+				continue
+			}
+			matched++
+			if t.Status == "pass" {
+				passed++
+			}
+		}
+		switch {
+		case matched == 0:
+		case passed == matched:
+			score += tr.points
+		case rule.cfg.Partial:
+			score += tr.points * float64(passed) / float64(matched)
+		}
+	}
+	return round2(score), round2(maxScore)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func writeScores(p string, sum ScoreSummary) error {
+	b, err := json.MarshalIndent(sum, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"industry_backend_go/internal/config"
+	"testing"
+)
+
+func TestScorePackage(t *testing.T) {
+	t.Parallel()
+
+	pass := func(name string) *TestResult { return &TestResult{Name: name, Status: "pass"} }
+	fail := func(name string) *TestResult { return &TestResult{Name: name, Status: "fail"} }
+	results := []*TestResult{pass("TestA"), pass("TestA/sub"), fail("TestB"), pass("TestC")}
+
+	tests := []struct {
+		name     string
+		cfg      config.TaskScore
+		res      PackageResult
+		score    float64
+		maxScore float64
+	}{
+		{
+			name:  "pass gets all points",
+			cfg:   config.TaskScore{Points: 10},
+			res:   PackageResult{Status: "pass", Tests: results[:2]},
+			score: 10, maxScore: 10,
+		},
+		{
+			name:  "slow scores like pass",
+			cfg:   config.TaskScore{Points: 10},
+			res:   PackageResult{Status: "slow", Tests: results[:2]},
+			score: 10, maxScore: 10,
+		},
+		{
+			name:  "fail without partial",
+			cfg:   config.TaskScore{Points: 10},
+			res:   PackageResult{Status: "fail", Tests: results},
+			score: 0, maxScore: 10,
+		},
+		{
+			name:  "partial counts top-level tests only",
+			cfg:   config.TaskScore{Points: 10, Partial: true},
+			res:   PackageResult{Status: "fail", Tests: results},
+			score: 6.67, maxScore: 10,
+		},
+		{
+			name:  "partial gives nothing for a build failure",
+			cfg:   config.TaskScore{Points: 10, Partial: true},
+			res:   PackageResult{Status: "build_failed"},
+			score: 0, maxScore: 10,
+		},
+		{
+			name:  "partial gives nothing after a panic",
+			cfg:   config.TaskScore{Points: 10, Partial: true},
+			res:   PackageResult{Status: "panic", Tests: []*TestResult{pass("TestA"), fail("TestB")}},
+			score: 0, maxScore: 10,
+		},
+		{
+			name:  "partial gives nothing after a timeout",
+			cfg:   config.TaskScore{Points: 10, Partial: true},
+			res:   PackageResult{Status: "timeout", Tests: []*TestResult{pass("TestA"), pass("TestC")}},
+			score: 0, maxScore: 10,
+		},
+		{
+			name:  "partial still counts a data race",
+			cfg:   config.TaskScore{Points: 10, Partial: true},
+			res:   PackageResult{Status: "data_race", Tests: results},
+			score: 6.67, maxScore: 10,
+		},
+		{
+			name: "per-test points",
+			cfg: config.TaskScore{Tests: []config.TestScore{
+				{Pattern: "^TestA$", Points: 2},
+				{Pattern: "^Test[BC]$", Points: 4},
+			}},
+			res:   PackageResult{Status: "fail", Tests: results},
+			score: 2, maxScore: 6,
+		},
+		{
+			name: "per-test points, partial",
+			cfg: config.TaskScore{Partial: true, Tests: []config.TestScore{
+				{Pattern: "^Test[BC]$", Points: 4},
+			}},
+			res:   PackageResult{Status: "fail", Tests: results},
+			score: 2, maxScore: 4,
+		},
+		{
+			name: "per-test points after a panic",
+			cfg: config.TaskScore{Points: 1, Partial: true, Tests: []config.TestScore{
+				{Pattern: "^TestA$", Points: 2},
+				{Pattern: "^Test[BC]$", Points: 4},
+			}},
+			// TestC не запускался: без учёта panic группа ^Test[BC]$ прошла бы целиком
+			res:   PackageResult{Status: "panic", Tests: []*TestResult{pass("TestA"), pass("TestB")}},
+			score: 0, maxScore: 7,
+		},
+	}
+	for _, tt := range tests {
+		rules, err := compileScoring(config.Scoring{Tasks: []config.TaskScore{tt.cfg}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		score, maxScore := scorePackage(rules[0], &tt.res)
+		if score != tt.score || maxScore != tt.maxScore {
+			t.Errorf("%s: scorePackage() = %g/%g; want %g/%g", tt.name, score, maxScore, tt.score, tt.maxScore)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package changepolicy
+
+import (
+	"bytes"
+	"fmt"
+	"industry_backend_go/internal/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type FileStat struct {
+	Path     string   `json:"path"`
+	Added    int      `json:"added"`
+	Removed  int      `json:"removed"`
+	Binary   bool     `json:"binary,omitempty"`
+	Exceeded []string `json:"exceeded,omitempty"`
+}
+
+// DiffStats считает добавленные/удалённые строки по двум деревьям (аналог --numstat).
+func DiffStats(baseline, current string, changes []Change) ([]FileStat, error) {
+	read := func(root, p string) ([]byte, error) {
+		if p == "" {
+			return nil, nil
+		}
+		full := filepath.Join(root, filepath.FromSlash(p))
+		fi, err := os.Lstat(full)
+		switch {
+		case err != nil:
+			return nil, err
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(full)
+			return []byte(target), err
+		case fi.IsDir():
+			// gitlink
+			return nil, nil
+		}
+		return os.ReadFile(full)
+	}
+
+	var out []FileStat
+	for _, ch := range changes {
+		var oldPath, newPath string
+		switch statusLead(ch.Status) {
+		case "A":
+			newPath = ch.Path
+		case "D":
+			oldPath = ch.Path
+		case "R", "C":
+			oldPath, newPath = ch.From, ch.To
+		default:
+			oldPath, newPath = ch.Path, ch.Path
+		}
+
+		a, err := read(baseline, oldPath)
+		if err != nil {
+			return nil, err
+		}
+		b, err := read(current, newPath)
+		if err != nil {
+			return nil, err
+		}
+
+		st := FileStat{Path: changeKey(ch)}
+		if isBinary(a) || isBinary(b) {
+			st.Binary = true
+		} else {
+			st.Added, st.Removed = countLines(a, b)
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// ReadNumstat читает вывод git diff --numstat ("added\tremoved\tpath", для
+// бинарных файлов вместо чисел "-"). Поддерживается и вывод с -z, где rename
+// записывается как "added\tremoved\t\0from\0to\0". Пути чистятся как в
+// ParseChanges; у удалённого в --no-index файла (новое имя /dev/null) берётся старое.
+func ReadNumstat(p string) ([]FileStat, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	nul := bytes.IndexByte(b, 0) >= 0
+	sep := "\n"
+	if nul {
+		sep = "\x00"
+	}
+	tokens := strings.Split(string(b), sep)
+
+	var out []FileStat
+	for i := 0; i < len(tokens); i++ {
+		line := strings.TrimRight(tokens[i], "\r")
+		if nul {
+			line = strings.TrimLeft(line, "\r\n")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("numstat: bad line %q", line)
+		}
+
+		name := parts[2]
+		switch {
+		case nul && name == "":
+			// rename в -z: дальше идут старое и новое имя
+			if i+2 >= len(tokens) || tokens[i+2] == "" {
+				return nil, fmt.Errorf("numstat: truncated rename entry %q", line)
+			}
+			name = tokens[i+2]
+			if name == devNull {
+				name = tokens[i+1]
+			}
+			i += 2
+		case !nul:
+			name = numstatNewPath(name)
+		}
+
+		st := FileStat{Path: trimDiffSide(name)}
+		if parts[0] == "-" && parts[1] == "-" {
+			st.Binary = true
+		} else {
+			if st.Added, err = strconv.Atoi(parts[0]); err != nil {
+				return nil, fmt.Errorf("numstat: bad line %q", line)
+			}
+			if st.Removed, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("numstat: bad line %q", line)
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// devNull — вторая сторона добавленного или удалённого файла в git diff --no-index.
+const devNull = "/dev/null"
+
+// numstatNewPath достаёт новое имя из записи rename: "old => new" или "dir/{a => b}/f".
+// Для "old => /dev/null" (удаление в --no-index) — старое имя.
+func numstatNewPath(p string) string {
+	lb := strings.Index(p, "{")
+	rb := strings.LastIndex(p, "}")
+	if lb >= 0 && rb > lb && strings.Contains(p[lb:rb], " => ") {
+		_, to, _ := strings.Cut(p[lb+1:rb], " => ")
+		return strings.ReplaceAll(p[:lb]+to+p[rb+1:], "//", "/")
+	}
+	if from, to, ok := strings.Cut(p, " => "); ok {
+		if to = unquoteCPath(to); to == devNull {
+			return unquoteCPath(from)
+		}
+		return to
+	}
+	return unquoteCPath(p)
+}
+
+type budget struct {
+	config.DiffBudget
+	source string
+	re     *regexp.Regexp
+}
+
+func compileBudgets(budgets []config.DiffBudget) ([]budget, error) {
+	out := make([]budget, 0, len(budgets))
+	for i, b := range budgets {
+		source := fmt.Sprintf("budgets[%d]", i)
+		re, err := GlobToRegex(strings.TrimSpace(b.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("%s: pattern %q: %w", source, b.Pattern, err)
+		}
+		out = append(out, budget{DiffBudget: b, source: source, re: re})
+	}
+	return out, nil
+}
+
+// CheckBudgets проверяет статистику изменений по diff.budgets и кладёт её в отчёт.
+func (p *Policy) CheckBudgets(rep *Report, stats []FileStat) {
+	rep.Files = checkBudgets(stats, p.budgets)
+	rep.updateOK()
+}
+
+// checkBudgets заполняет Exceeded у каждой записи: проверяются все подходящие бюджеты.
+func checkBudgets(stats []FileStat, budgets []budget) []FileStat {
+	for i := range stats {
+		st := &stats[i]
+		for _, b := range budgets {
+			if !b.re.MatchString(st.Path) {
+				continue
+			}
+			exceeded := func(what string, got, limit int) {
+				st.Exceeded = append(st.Exceeded, fmt.Sprintf("%s %q: %s %d > %d", b.source, b.Pattern, what, got, limit))
+			}
+			if b.NoBinary && st.Binary {
+				st.Exceeded = append(st.Exceeded, fmt.Sprintf("%s %q: binary files are not allowed", b.source, b.Pattern))
+			}
+			if b.MaxAdded > 0 && st.Added > b.MaxAdded {
+				exceeded("added", st.Added, b.MaxAdded)
+			}
+			if b.MaxRemoved > 0 && st.Removed > b.MaxRemoved {
+				exceeded("removed", st.Removed, b.MaxRemoved)
+			}
+			if b.MaxChanged > 0 && st.Added+st.Removed > b.MaxChanged {
+				exceeded("changed", st.Added+st.Removed, b.MaxChanged)
+			}
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Path < stats[j].Path })
+	return stats
+}
+
+// BudgetViolations — число файлов, превысивших хотя бы один бюджет.
+func (r Report) BudgetViolations() int {
+	n := 0
+	for _, st := range r.Files {
+		if len(st.Exceeded) > 0 {
+			n++
+		}
+	}
+	return n
+}
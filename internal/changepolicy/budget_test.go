@@ -0,0 +1,189 @@
+package changepolicy
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountLines(t *testing.T) {
+	t.Parallel()
+
+	// ожидаемые значения — вывод git diff --no-index --numstat
+	tests := []struct {
+		a, b           string
+		added, removed int
+	}{
+		{"", "a\nb\n", 2, 0},
+		{"a\nb\n", "", 0, 2},
+		{"a\nb\n", "a\nb\n", 0, 0},
+		{"a\nb\nc\n", "a\nx\nc\n", 1, 1},
+		{"a", "a\n", 1, 1}, // \ No newline at end of file
+		{"a\nb\nc\nd\n", "b\nc\nd\ne\n", 1, 1},
+		{"a\r\nb\n", "a\nb\n", 1, 1},
+		{"a\nb\nc\n", "c\na\nb\n", 1, 1},
+		{"x\nx\nx\n", "x\nx\n", 0, 1},
+		{"a\nb\nc\nd\ne\nf\n", "a\nc\nX\ne\nY\nf\n", 2, 2},
+	}
+	for _, tt := range tests {
+		added, removed := countLines([]byte(tt.a), []byte(tt.b))
+		if added != tt.added || removed != tt.removed {
+			t.Errorf("countLines(%q, %q) = +%d -%d; want +%d -%d", tt.a, tt.b, added, removed, tt.added, tt.removed)
+		}
+	}
+}
+
+// diffLines должен давать минимальный diff (как git): число общих строк
+// равно длине LCS, а операции восстанавливают обе версии.
+func TestDiffLinesMinimal(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	randLines := func() []string {
+		out := make([]string, rnd.Intn(12))
+		for i := range out {
+			out[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return out
+	}
+	for i := 0; i < 300; i++ {
+		a, b := randLines(), randLines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		common := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				common++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diffLines(%q, %q) = %v: does not rebuild both sides", a, b, ops)
+		}
+		if want := lcsLen(a, b); common != want {
+			t.Fatalf("diffLines(%q, %q): %d common lines; want %d", a, b, common, want)
+		}
+	}
+}
+
+func lcsLen(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestReadNumstat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []FileStat
+		ok   bool
+	}{
+		{
+			name: "line-based",
+			in: "3\t1\ttasks/task_01/solution.go\r\n" +
+				"-\t-\tdocs/logo.png\n" +
+				"2\t0\t\"tasks/\\320\\237.go\"\n",
+			want: []FileStat{
+				{Path: "tasks/task_01/solution.go", Added: 3, Removed: 1},
+				{Path: "docs/logo.png", Binary: true},
+				{Path: "tasks/П.go", Added: 2},
+			},
+			ok: true,
+		},
+		{
+			name: "renames",
+			in: "5\t0\ttasks/{task_01 => task_02}/solution.go\n" +
+				"0\t0\tdir/{ => sub}/f.go\n" +
+				"0\t0\tdir/{sub => }/f.go\n" +
+				"1\t1\told.go => new.go\n",
+			want: []FileStat{
+				{Path: "tasks/task_02/solution.go", Added: 5},
+				{Path: "dir/sub/f.go"},
+				{Path: "dir/f.go"},
+				{Path: "new.go", Added: 1, Removed: 1},
+			},
+			ok: true,
+		},
+		{
+			name: "-z",
+			in:   "3\t1\ttasks/a b.go\x00-\t-\tbin.dat\x005\t0\t\x00old name.go\x00new\tname.go\x00",
+			want: []FileStat{
+				{Path: "tasks/a b.go", Added: 3, Removed: 1},
+				{Path: "bin.dat", Binary: true},
+				{Path: "new\tname.go", Added: 5},
+			},
+			ok: true,
+		},
+		{
+			name: "git diff --no-index",
+			in: "2\t1\t{../baseline => .}/tasks/task_01/solution.go\n" +
+				"0\t1\t../baseline/gone.txt => /dev/null\n" +
+				"1\t0\t/dev/null => \"./back\\\\slash.go\"\n" +
+				"0\t0\t../baseline/r/old.txt => ./r/new.txt\n" +
+				"1\t0\t/dev/null => ./current/x.go\n",
+			want: []FileStat{
+				{Path: "tasks/task_01/solution.go", Added: 2, Removed: 1},
+				{Path: "gone.txt", Removed: 1},
+				{Path: `back\slash.go`, Added: 1},
+				{Path: "r/new.txt"},
+				{Path: "current/x.go", Added: 1},
+			},
+			ok: true,
+		},
+		{
+			name: "git diff --no-index -z",
+			in: "2\t1\t\x00../baseline/tasks/task_01/solution.go\x00./tasks/task_01/solution.go\x00" +
+				"0\t1\t\x00../baseline/gone.txt\x00/dev/null\x00" +
+				"1\t0\t\x00/dev/null\x00./sp ace.go\x00",
+			want: []FileStat{
+				{Path: "tasks/task_01/solution.go", Added: 2, Removed: 1},
+				{Path: "gone.txt", Removed: 1},
+				{Path: "sp ace.go", Added: 1},
+			},
+			ok: true,
+		},
+		{name: "not a number", in: "x\t1\tf.go\n"},
+		{name: "missing path", in: "1\t1\n"},
+		{name: "truncated -z rename", in: "5\t0\t\x00old.go\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := filepath.Join(t.TempDir(), "numstat")
+			if err := os.WriteFile(p, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadNumstat(p)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ReadNumstat() error = %v; want ok %v", err, tt.ok)
+			}
+			if tt.ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadNumstat() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package changepolicy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// renameThreshold — минимальная похожесть (в процентах) для rename, как -M50% у git.
+const renameThreshold = 50
+
+type treeFile struct {
+	size   int64
+	hash   string
+	mode   string // режим в терминах git: 100644/100755/120000/160000
+	target string // цель симлинка
+}
+
+func (f treeFile) link() bool { return f.mode == modeSymlink || f.mode == modeGitlink }
+
+// CompareDirs сравнивает два дерева без git и возвращает изменения в формате
+// git diff --name-status: A/M/D и R<score> для переименований.
+func CompareDirs(baseline, current string) ([]Change, error) {
+	oldFiles, err := scanTree(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	newFiles, err := scanTree(current)
+	if err != nil {
+		return nil, fmt.Errorf("current: %w", err)
+	}
+
+	var out []Change
+	var deleted, added []string
+	for p, of := range oldFiles {
+		nf, ok := newFiles[p]
+		if !ok {
+			deleted = append(deleted, p)
+			continue
+		}
+		switch {
+		case modeType(of.mode) != modeType(nf.mode):
+			out = append(out, withModes(newChange("T", p, ""), of, nf))
+		case of.hash != nf.hash || of.mode != nf.mode:
+			out = append(out, withModes(newChange("M", p, ""), of, nf))
+		}
+	}
+	for p := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	sort.Strings(deleted)
+	sort.Strings(added)
+
+	renames, err := detectRenames(baseline, current, oldFiles, newFiles, deleted, added)
+	if err != nil {
+		return nil, err
+	}
+	renamedFrom := map[string]struct{}{}
+	renamedTo := map[string]struct{}{}
+	for _, r := range renames {
+		renamedFrom[r.From] = struct{}{}
+		renamedTo[r.To] = struct{}{}
+		out = append(out, withModes(r, oldFiles[r.From], newFiles[r.To]))
+	}
+	for _, p := range deleted {
+		if _, ok := renamedFrom[p]; !ok {
+			out = append(out, withModes(newChange("D", p, ""), oldFiles[p], treeFile{}))
+		}
+	}
+	for _, p := range added {
+		if _, ok := renamedTo[p]; !ok {
+			out = append(out, withModes(newChange("A", p, ""), treeFile{}, newFiles[p]))
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return changeKey(out[i]) < changeKey(out[j])
+	})
+	return out, nil
+}
+
+func newChange(status, p, to string) Change {
+	if to != "" {
+		return Change{Status: status, From: p, To: to, Raw: status + "\t" + p + "\t" + to}
+	}
+	return Change{Status: status, Path: p, Raw: status + "\t" + p}
+}
+
+func withModes(ch Change, oldFile, newFile treeFile) Change {
+	ch.OldMode = oldFile.mode
+	ch.NewMode = newFile.mode
+	ch.Target = newFile.target
+	return ch
+}
+
+func changeKey(ch Change) string {
+	if ch.Path != "" {
+		return ch.Path
+	}
+	return ch.To
+}
+
+// scanTree собирает файлы дерева (пути через /, относительно root), пропуская .git.
+// Вложенный git-репозиторий записывается как gitlink, внутрь не заходим.
+func scanTree(root string) (map[string]treeFile, error) {
+	out := map[string]treeFile{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Lstat(filepath.Join(p, ".git")); err == nil {
+				out[rel] = treeFile{hash: "gitlink", mode: modeGitlink}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var content []byte
+		f := treeFile{mode: modeFile}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			f.mode = modeSymlink
+			f.target = filepath.ToSlash(target)
+			content = []byte("symlink:" + target)
+		case d.Type().IsRegular():
+			content, err = os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Mode()&0o111 != 0 {
+				f.mode = modeExec
+			}
+		default:
+			return nil
+		}
+
+		sum := sha256.Sum256(content)
+		f.size = int64(len(content))
+		f.hash = hex.EncodeToString(sum[:])
+		out[rel] = f
+		return nil
+	})
+	return out, err
+}
+
+// detectRenames сопоставляет удалённые и добавленные файлы: сначала точные
+// совпадения по хэшу, затем по похожести содержимого (жадно, лучшие пары первыми).
+func detectRenames(baseline, current string, oldFiles, newFiles map[string]treeFile, deleted, added []string) ([]Change, error) {
+	var out []Change
+	usedFrom := map[string]struct{}{}
+	usedTo := map[string]struct{}{}
+
+	byHash := map[string][]string{}
+	for _, p := range deleted {
+		h := oldFiles[p].hash
+		byHash[h] = append(byHash[h], p)
+	}
+	for _, to := range added {
+		cands := byHash[newFiles[to].hash]
+		if len(cands) == 0 {
+			continue
+		}
+		from := cands[0]
+		byHash[newFiles[to].hash] = cands[1:]
+		usedFrom[from] = struct{}{}
+		usedTo[to] = struct{}{}
+		out = append(out, newChange("R100", from, to))
+	}
+
+	type pair struct {
+		from, to string
+		score    int
+	}
+	var pairs []pair
+	contents := map[string][]byte{}
+	read := func(root, p string) ([]byte, error) {
+		key := root + "\x00" + p
+		if b, ok := contents[key]; ok {
+			return b, nil
+		}
+		b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		contents[key] = b
+		return b, nil
+	}
+	for _, from := range deleted {
+		if _, ok := usedFrom[from]; ok || oldFiles[from].size == 0 || oldFiles[from].link() {
+			continue
+		}
+		for _, to := range added {
+			if _, ok := usedTo[to]; ok || newFiles[to].size == 0 || newFiles[to].link() {
+				continue
+			}
+			a, err := read(baseline, from)
+			if err != nil {
+				return nil, err
+			}
+			b, err := read(current, to)
+			if err != nil {
+				return nil, err
+			}
+			if score := similarity(a, b); score >= renameThreshold {
+				pairs = append(pairs, pair{from: from, to: to, score: score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+	for _, pr := range pairs {
+		if _, ok := usedFrom[pr.from]; ok {
+			continue
+		}
+		if _, ok := usedTo[pr.to]; ok {
+			continue
+		}
+		usedFrom[pr.from] = struct{}{}
+		usedTo[pr.to] = struct{}{}
+		out = append(out, newChange(fmt.Sprintf("R%03d", pr.score), pr.from, pr.to))
+	}
+	return out, nil
+}
+
+// similarity — доля общих строк (в байтах) от большего файла, 0..100.
+func similarity(a, b []byte) int {
+	maxSize := max(len(a), len(b))
+	if maxSize == 0 {
+		return 100
+	}
+	counts := map[string]int{}
+	for _, l := range splitLines(a) {
+		counts[string(l)]++
+	}
+	common := 0
+	for _, l := range splitLines(b) {
+		if counts[string(l)] > 0 {
+			counts[string(l)]--
+			common += len(l)
+		}
+	}
+	return common * 100 / maxSize
+}
+
+// splitLines режет по \n, оставляя перевод строки в конце каждой строки.
+func splitLines(b []byte) [][]byte {
+	var out [][]byte
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			out = append(out, b)
+			break
+		}
+		out = append(out, b[:i+1])
+		b = b[i+1:]
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package changepolicy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCompareDirs(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("line of code\n", 20)
+	baseline := writeTree(t, map[string]string{
+		"README.md":                  "readme\n",
+		"tasks/task_01/solution.go":  "package main\n",
+		"tasks/task_02/solution.go":  body,
+		"tasks/task_03/solution.go":  body + "tail\n",
+		"tasks/task_04/main.go":      "package main\n",
+		".git/HEAD":                  "ref: refs/heads/main\n",
+		"tasks/task_05/unchanged.go": "x\n",
+	})
+	current := writeTree(t, map[string]string{
+		"README.md":                  "readme\n",
+		"tasks/task_01/solution.go":  "package main\n\nfunc f() {}\n",
+		"tasks/task_02/moved.go":     body,
+		"tasks/task_03/renamed.go":   body + "other tail\n",
+		"tasks/task_06/new.go":       "package task06\n",
+		".git/HEAD":                  "ref: refs/heads/feature\n",
+		"tasks/task_05/unchanged.go": "x\n",
+	})
+
+	got, err := CompareDirs(baseline, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, ch := range got {
+		statuses = append(statuses, ch.Raw)
+	}
+	want := []string{
+		"M\ttasks/task_01/solution.go",
+		"R100\ttasks/task_02/solution.go\ttasks/task_02/moved.go",
+		"R095\ttasks/task_03/solution.go\ttasks/task_03/renamed.go",
+		"D\ttasks/task_04/main.go",
+		"A\ttasks/task_06/new.go",
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("CompareDirs() =\n%q\nwant\n%q", statuses, want)
+	}
+}
+
+// Пути из дерева не чистятся: файл "a/tasks/..." — новый пакет, а не решение.
+func TestCompareDirsExactPaths(t *testing.T) {
+	t.Parallel()
+
+	baseline := writeTree(t, map[string]string{"README.md": "readme\n"})
+	current := writeTree(t, map[string]string{
+		"README.md":                         "readme\n",
+		"a/tasks/task_01/solution.go":       "package main\n",
+		"current/tasks/task_02/solution.go": "package main\n",
+		"tasks/task_01/solution.go ":        "package main\n",
+	})
+
+	changes, err := CompareDirs(baseline, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := Evaluate(testConfig(), changes)
+	want := []string{
+		"a/tasks/task_01/solution.go",
+		"current/tasks/task_02/solution.go",
+		"tasks/task_01/solution.go ",
+	}
+	if rep.OK || !reflect.DeepEqual(rep.Unexpected, want) {
+		t.Fatalf("Evaluate(CompareDirs()) = ok %v, unexpected %q; want %q", rep.OK, rep.Unexpected, want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 100},
+		{"a\nb\n", "a\nb\n", 100},
+		{"a\nb\n", "c\nd\n", 0},
+		{"a\nb\n", "a\nc\n", 50},
+		{"a\n", "a\nb\n", 50},
+	}
+	for _, tt := range tests {
+		if got := similarity([]byte(tt.a), []byte(tt.b)); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
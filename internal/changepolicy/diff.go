@@ -0,0 +1,290 @@
+package changepolicy
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ReadChanges читает вывод git diff --name-status / --raw (построчный или -z).
+func ReadChanges(diffFile string) ([]Change, error) {
+	b, err := os.ReadFile(diffFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseChanges(b), nil
+}
+
+// ParseChanges сам определяет формат: если во входе есть NUL — это вывод
+// git diff -z, иначе построчный вывод (пути могут быть в C-кавычках git).
+// У вывода git (через таб, --raw, -z) и путей в кавычках снимается только
+// префикс стороны --no-index (trimDiffSide), остальные байты — часть имени:
+// пробелы, \ и кавычки. NormalizePath чистит только самодельные списки
+// "M b/tasks/x.go".
+func ParseChanges(b []byte) []Change {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return parseNULChanges(string(b))
+	}
+
+	var out []Change
+	for _, raw := range strings.Split(string(b), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		ch, ok := ParseDiffLine(raw)
+		if !ok {
+			// если формат непонятен — считаем как "изменённый файл = вся строка"
+			p := NormalizePath(unquoteCPath(line))
+			if p != "" {
+				out = append(out, Change{Status: "?", Path: p, Raw: raw})
+			}
+			continue
+		}
+		if !strings.Contains(raw, "\t") && !strings.Contains(raw, `"`) {
+			ch.Path, ch.From, ch.To = NormalizePath(ch.Path), NormalizePath(ch.From), NormalizePath(ch.To)
+		} else {
+			trimChangeSides(&ch)
+		}
+		out = append(out, ch)
+	}
+	return out
+}
+
+// diffSidePrefixes — префиксы сторон git diff --no-index: в CI это
+// "git -C current diff --no-index ../baseline .", из родительского каталога —
+// "git diff --no-index baseline current".
+var diffSidePrefixes = []string{"../baseline/", "./", "baseline/", "current/"}
+
+// trimDiffSide снимает один префикс стороны. Только один: файл студента
+// current/tasks/x.go в выводе CI — "./current/tasks/x.go", и он должен
+// остаться current/tasks/x.go, а не совпасть с tasks/x.go.
+func trimDiffSide(p string) string {
+	for _, pref := range diffSidePrefixes {
+		if rest, ok := strings.CutPrefix(p, pref); ok && rest != "" {
+			return rest
+		}
+	}
+	return p
+}
+
+func trimChangeSides(ch *Change) {
+	ch.Path, ch.From, ch.To = trimDiffSide(ch.Path), trimDiffSide(ch.From), trimDiffSide(ch.To)
+}
+
+var statusRe = regexp.MustCompile(`^[ACDMRTUXB][0-9]{0,3}$`)
+
+// parseNULChanges разбирает git diff -z: --name-status ("M\0path\0R100\0from\0to\0"),
+// --raw (":100644 100644 sha sha M\0path\0") и --name-only ("path\0").
+func parseNULChanges(s string) []Change {
+	tokens := strings.Split(s, "\x00")
+	var out []Change
+	for i := 0; i < len(tokens); i++ {
+		tok := strings.TrimLeft(tokens[i], "\r\n")
+		if tok == "" {
+			continue
+		}
+
+		ch := Change{}
+		switch {
+		case strings.HasPrefix(tok, ":"):
+			fields := strings.Fields(tok[1:])
+			if len(fields) != 5 {
+				out = append(out, Change{Status: "?", Path: trimDiffSide(tok), Raw: tok})
+				continue
+			}
+			ch = Change{Status: fields[4], OldMode: rawMode(fields[0]), NewMode: rawMode(fields[1])}
+		case statusRe.MatchString(tok):
+			ch.Status = tok
+		default:
+			out = append(out, Change{Status: "?", Path: trimDiffSide(tok), Raw: tok})
+			continue
+		}
+
+		need := 1
+		if lead := statusLead(ch.Status); lead == "R" || lead == "C" {
+			need = 2
+		}
+		if i+need >= len(tokens) {
+			break
+		}
+		paths := tokens[i+1 : i+1+need]
+		i += need
+		if need == 2 {
+			ch.From, ch.To = paths[0], paths[1]
+		} else {
+			ch.Path = paths[0]
+		}
+		ch.Raw = strings.Join(append([]string{tok}, paths...), "\t")
+		trimChangeSides(&ch)
+		out = append(out, ch)
+	}
+	return out
+}
+
+func ParseDiffLine(raw string) (Change, bool) {
+	line := strings.TrimRight(raw, "\r\n")
+	if strings.HasPrefix(line, ":") {
+		return parseRawDiffLine(line, raw)
+	}
+	var parts []string
+
+	// предпочтительно таб-разделение (как в changed_files.raw)
+	if strings.Contains(line, "\t") {
+		parts = strings.Split(line, "\t")
+	} else {
+		parts = splitSpaced(line)
+	}
+
+	if len(parts) < 2 {
+		return Change{}, false
+	}
+
+	st := parts[0]
+	ch := Change{Status: st, Raw: raw}
+
+	lead := statusLead(st)
+	if lead == "R" || lead == "C" {
+		if len(parts) < 3 {
+			return Change{}, false
+		}
+		ch.From = unquoteCPath(parts[1])
+		ch.To = unquoteCPath(parts[2])
+		return ch, true
+	}
+
+	ch.Path = unquoteCPath(parts[1])
+	return ch, true
+}
+
+// splitSpaced делит строку без табов: пути в кавычках git — один токен,
+// а для статусов с одним путём остаток строки целиком считается путём.
+func splitSpaced(line string) []string {
+	st, rest, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok {
+		return []string{st}
+	}
+	rest = strings.TrimSpace(rest)
+	lead := statusLead(st)
+	if lead != "R" && lead != "C" && !strings.HasPrefix(rest, `"`) {
+		return []string{st, rest}
+	}
+
+	parts := []string{st}
+	for rest != "" {
+		var tok string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuote(rest)
+			if end < 0 {
+				tok, rest = rest, ""
+			} else {
+				tok, rest = rest[:end+1], rest[end+1:]
+			}
+		} else {
+			tok, rest, _ = strings.Cut(rest, " ")
+		}
+		parts = append(parts, tok)
+		rest = strings.TrimLeft(rest, " ")
+	}
+	return parts
+}
+
+// closingQuote — индекс закрывающей кавычки строки s, начинающейся с '"'.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteCPath снимает C-кавычки, которыми git экранирует необычные пути:
+// "tasks/\320\237.go", "a\tb", "quote\"d".
+func unquoteCPath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch != '\\' || i+1 == len(s) {
+			b.WriteByte(ch)
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '0', '1', '2', '3':
+			if i+2 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) {
+				b.WriteByte((c-'0')<<6 | (s[i+1]-'0')<<3 | (s[i+2] - '0'))
+				i += 2
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			// \" \\ и всё прочее — символ как есть
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// parseRawDiffLine разбирает формат git diff --raw:
+// ":100644 100755 <sha> <sha> M\tpath" (для R/C — два пути).
+func parseRawDiffLine(line, raw string) (Change, bool) {
+	meta, rest, ok := strings.Cut(line[1:], "\t")
+	if !ok {
+		return Change{}, false
+	}
+	fields := strings.Fields(meta)
+	if len(fields) != 5 {
+		return Change{}, false
+	}
+	paths := strings.Split(rest, "\t")
+
+	ch := Change{Status: fields[4], OldMode: rawMode(fields[0]), NewMode: rawMode(fields[1]), Raw: raw}
+	lead := statusLead(ch.Status)
+	if lead == "R" || lead == "C" {
+		if len(paths) < 2 {
+			return Change{}, false
+		}
+		ch.From, ch.To = unquoteCPath(paths[0]), unquoteCPath(paths[1])
+		return ch, true
+	}
+	ch.Path = unquoteCPath(paths[0])
+	return ch, ch.Path != ""
+}
+
+// rawMode: "000000" в --raw означает отсутствие файла с этой стороны.
+func rawMode(m string) string {
+	if m == "000000" {
+		return ""
+	}
+	return m
+}
@@ -0,0 +1,256 @@
+package changepolicy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cQuote квотирует путь так же, как git с core.quotePath=true.
+func cQuote(p string) string {
+	needs := false
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			needs = true
+			break
+		}
+	}
+	if !needs {
+		return p
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, `\%03o`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func TestParseDiffLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line string
+		want Change
+		ok   bool
+	}{
+		{"M\ttasks/task_01/solution.go", Change{Status: "M", Path: "tasks/task_01/solution.go"}, true},
+		{"M tasks/task_01/solution.go", Change{Status: "M", Path: "tasks/task_01/solution.go"}, true},
+		{"A\tdir with spaces/file name.go", Change{Status: "A", Path: "dir with spaces/file name.go"}, true},
+		{"A dir with spaces/file name.go", Change{Status: "A", Path: "dir with spaces/file name.go"}, true},
+		{"R087\told.go\tnew.go", Change{Status: "R087", From: "old.go", To: "new.go"}, true},
+		{`R100 "a b.go" "c\td.go"`, Change{Status: "R100", From: "a b.go", To: "c\td.go"}, true},
+		{"M\t\"tasks/\\320\\237.go\"", Change{Status: "M", Path: "tasks/П.go"}, true},
+		{"M\t\"with\\\"quote\\\\slash\\nnewline\"", Change{Status: "M", Path: "with\"quote\\slash\nnewline"}, true},
+		{":100644 100755 aaa bbb M\ttasks/x.go", Change{Status: "M", Path: "tasks/x.go", OldMode: "100644", NewMode: "100755"}, true},
+		{":000000 100644 000 bbb A\t\"tasks/\\303\\251.go\"", Change{Status: "A", Path: "tasks/é.go", NewMode: "100644"}, true},
+		{":100644 100644 aaa bbb R090\ta.go\tb.go", Change{Status: "R090", From: "a.go", To: "b.go", OldMode: "100644", NewMode: "100644"}, true},
+		{"R100\tonly-one.go", Change{}, false},
+		{"justapath", Change{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDiffLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("ParseDiffLine(%q) ok = %v; want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		got.Raw = ""
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDiffLine(%q) = %+v; want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseChangesPaths(t *testing.T) {
+	t.Parallel()
+
+	got := ParseChanges([]byte("M b/tasks/task_02/solution.go\r\n" +
+		"A\ta/tasks/task_01/solution.go\n" +
+		"M\t\"tasks/task_01/solution.go \"\n" +
+		"M \"a\\\\b.go\"\n"))
+	for i := range got {
+		got[i].Raw = ""
+	}
+	want := []Change{
+		{Status: "M", Path: "tasks/task_02/solution.go"},
+		{Status: "A", Path: "a/tasks/task_01/solution.go"},
+		{Status: "M", Path: "tasks/task_01/solution.go "},
+		{Status: "M", Path: `a\b.go`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseChanges() = %+v; want %+v", got, want)
+	}
+}
+
+// Вывод "git -C current diff --no-index ../baseline ." как в CI: снимается
+// только префикс стороны, файл current/... студента остаётся своим путём.
+func TestParseChangesNoIndex(t *testing.T) {
+	t.Parallel()
+
+	want := []Change{
+		{Status: "A", Path: `back\slash.go`},
+		{Status: "A", Path: "current/tasks/task_02/solution.go"},
+		{Status: "D", Path: "gone.txt"},
+		{Status: "R100", From: "r/old.txt", To: "r/new.txt"},
+		{Status: "A", Path: "sp ace.go"},
+		{Status: "M", Path: "tasks/task_01/solution.go"},
+	}
+	inputs := map[string]string{
+		"name-status": "A\t\"./back\\\\slash.go\"\n" +
+			"A\t./current/tasks/task_02/solution.go\n" +
+			"D\t../baseline/gone.txt\n" +
+			"R100\t../baseline/r/old.txt\t./r/new.txt\n" +
+			"A\t./sp ace.go\n" +
+			"M\t../baseline/tasks/task_01/solution.go\n",
+		"name-status -z": "A\x00./back\\slash.go\x00" +
+			"A\x00./current/tasks/task_02/solution.go\x00" +
+			"D\x00../baseline/gone.txt\x00" +
+			"R100\x00../baseline/r/old.txt\x00./r/new.txt\x00" +
+			"A\x00./sp ace.go\x00" +
+			"M\x00../baseline/tasks/task_01/solution.go\x00",
+		"raw": ":000000 100644 0000000 bca70f3 A\t\"./back\\\\slash.go\"\n" +
+			":000000 100644 0000000 bca70f3 A\t./current/tasks/task_02/solution.go\n" +
+			":100644 000000 587be6b 0000000 D\t../baseline/gone.txt\n" +
+			":100644 100644 0ff3bbb 0ff3bbb R100\t../baseline/r/old.txt\t./r/new.txt\n" +
+			":000000 100644 0000000 bca70f3 A\t./sp ace.go\n" +
+			":100644 100644 0000000 0000000 M\t../baseline/tasks/task_01/solution.go\n",
+	}
+	for name, in := range inputs {
+		got := ParseChanges([]byte(in))
+		for i := range got {
+			got[i].Raw, got[i].OldMode, got[i].NewMode = "", "", ""
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ParseChanges() =\n%+v\nwant\n%+v", name, got, want)
+		}
+	}
+
+	rep := Evaluate(testConfig(), ParseChanges([]byte(inputs["name-status"])))
+	if d := rep.Decisions[len(rep.Decisions)-1]; d.Path != "tasks/task_01/solution.go" || !d.Allowed {
+		t.Errorf("decision for the solution = %+v; want allowed", d)
+	}
+}
+
+func TestParseChangesNUL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []Change
+	}{
+		{
+			name: "name-status",
+			in:   "M\x00a\tb.go\x00R100\x00old name.go\x00new\nname.go\x00D\x00gone.go\x00",
+			want: []Change{
+				{Status: "M", Path: "a\tb.go"},
+				{Status: "R100", From: "old name.go", To: "new\nname.go"},
+				{Status: "D", Path: "gone.go"},
+			},
+		},
+		{
+			name: "raw",
+			in:   ":100644 100755 aaa bbb M\x00run.sh\x00:100644 100644 aaa bbb C075\x00a.go\x00b.go\x00",
+			want: []Change{
+				{Status: "M", Path: "run.sh", OldMode: "100644", NewMode: "100755"},
+				{Status: "C075", From: "a.go", To: "b.go", OldMode: "100644", NewMode: "100644"},
+			},
+		},
+		{
+			name: "name-only",
+			in:   "tasks/П.go\x00x y.go\x00",
+			want: []Change{
+				{Status: "?", Path: "tasks/П.go"},
+				{Status: "?", Path: "x y.go"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := ParseChanges([]byte(tt.in))
+		for i := range got {
+			got[i].Raw = ""
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseChanges() = %+v; want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func FuzzParseDiffLine(f *testing.F) {
+	for _, seed := range []string{
+		"tasks/task_01/solution.go",
+		"dir with spaces/f.go",
+		"tasks/П.go",
+		"a\tb\nc\"d\\e",
+		"\x00\x01\x7f\xff",
+	} {
+		f.Add("M", seed)
+		f.Add("R100", seed)
+	}
+
+	f.Fuzz(func(t *testing.T, status, p string) {
+		// разбор произвольной строки не должен паниковать
+		ParseDiffLine(status + "\t" + p)
+		ParseDiffLine(status + " " + p)
+		ParseChanges([]byte(status + "\x00" + p + "\x00"))
+
+		if p == "" || strings.ContainsRune(p, 0) {
+			return
+		}
+		quoted := cQuote(p)
+		if got := unquoteCPath(quoted); got != p {
+			t.Fatalf("unquoteCPath(%q) = %q; want %q", quoted, got, p)
+		}
+
+		// путь, экранированный как в git, должен восстанавливаться без потерь
+		line := "M\t" + quoted
+		ch, ok := ParseDiffLine(line)
+		if !ok || ch.Path != p {
+			t.Fatalf("ParseDiffLine(%q) = %+v, %v; want path %q", line, ch, ok, p)
+		}
+
+		// и именно он попадает в проверку правил
+		changes := ParseChanges([]byte(line + "\n"))
+		rep := Evaluate(testConfig(), changes)
+		if !reflect.DeepEqual(rep.ChangedPaths, []string{p}) {
+			t.Fatalf("Evaluate(ParseChanges(%q)).ChangedPaths = %q; want [%q]", line, rep.ChangedPaths, p)
+		}
+
+		line = "R100\t" + quoted + "\t" + quoted
+		ch, ok = ParseDiffLine(line)
+		if !ok || ch.From != p || ch.To != p {
+			t.Fatalf("ParseDiffLine(%q) = %+v, %v; want both paths %q", line, ch, ok, p)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package changepolicy
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+var digitsRe = regexp.MustCompile(`[0-9]+`)
+
+// RuleMatch — как одно правило относится к пути.
+type RuleMatch struct {
+	Rule    string `json:"rule"`
+	Verdict string `json:"verdict"`
+}
+
+// Explanation — разбор одного пути для подкоманды explain.
+type Explanation struct {
+	Path       string      `json:"path"`
+	Normalized string      `json:"normalized,omitempty"` // только если отличается от Path
+	Status     string      `json:"status"`
+	Matches    []RuleMatch `json:"matches,omitempty"`
+	Skipped    int         `json:"skipped"` // правила, не имеющие отношения к пути
+	Decision   Decision    `json:"decision"`
+}
+
+// Explain показывает, какие правила совпали с путём или почти совпали
+// (регистр, номер задания, то же имя файла), и итоговое решение. Решение
+// принимается по пути как есть, как в Evaluate; очищенный NormalizePath
+// путь служит только подсказкой.
+func (p *Policy) Explain(raw, status string) Explanation {
+	e := Explanation{Path: raw, Status: status}
+	if n := NormalizePath(raw); n != raw {
+		e.Normalized = n
+	}
+	e.Decision = decide(raw, status, p.matchers)
+	if raw == "" {
+		return e
+	}
+
+	// почти-совпадения показываем, только если ничего не совпало точно
+	matched := false
+	for _, m := range p.matchers {
+		if m.re.MatchString(raw) && m.appliesTo(status) {
+			matched = true
+			break
+		}
+	}
+
+	for _, m := range p.matchers {
+		verdict := explainMatch(m, raw, status)
+		if e.Normalized != "" && !m.re.MatchString(raw) && m.re.MatchString(e.Normalized) {
+			verdict = "near miss: matches after normalization"
+		}
+		if verdict == "" || (matched && !m.re.MatchString(raw)) {
+			e.Skipped++
+			continue
+		}
+		e.Matches = append(e.Matches, RuleMatch{Rule: m.String(), Verdict: verdict})
+	}
+	return e
+}
+
+// explainMatch возвращает пустую строку, если правило не имеет отношения к пути.
+func explainMatch(m matcher, p, status string) string {
+	if m.re.MatchString(p) {
+		if !m.appliesTo(status) {
+			return "matches, but not for status " + status
+		}
+		return "MATCH"
+	}
+
+	var near []string
+	digitsPath := digitsRe.ReplaceAllString(p, "0")
+	digitsGlob, err := GlobToRegex(digitsRe.ReplaceAllString(m.pattern, "0"))
+	if err != nil {
+		digitsGlob = m.re
+	}
+	switch {
+	case matchesFold(m.re, p):
+		near = append(near, "letter case differs")
+	case digitsGlob.MatchString(digitsPath):
+		near = append(near, "number differs")
+	case matchesFold(digitsGlob, digitsPath):
+		near = append(near, "letter case and number differ")
+	}
+	if len(near) == 0 {
+		base := path.Base(m.pattern)
+		if !strings.ContainsAny(base, `*?[{\`) && strings.EqualFold(base, path.Base(p)) {
+			near = append(near, "same file name, other directory")
+		}
+	}
+	if len(near) == 0 {
+		return ""
+	}
+	return "near miss: " + strings.Join(near, ", ")
+}
+
+func matchesFold(re *regexp.Regexp, p string) bool {
+	fold, err := regexp.Compile("(?i)" + re.String())
+	return err == nil && fold.MatchString(p)
+}
@@ -0,0 +1,228 @@
+package changepolicy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"industry_backend_go/internal/outfmt"
+	"os"
+	"strings"
+)
+
+// Formats — форматы отчёта change_check (-format).
+var Formats = outfmt.Set{
+	{Name: "json", Ext: ".json"},
+	{Name: "sarif", Ext: ".sarif"},
+	{Name: "junit", Ext: ".xml"},
+}
+
+func WriteReport(p, format string, rep Report) error {
+	switch format {
+	case "sarif":
+		return writeJSON(p, toSARIF(rep))
+	case "junit":
+		return writeXML(p, toJUnit(rep))
+	default:
+		return writeJSON(p, rep)
+	}
+}
+
+func writeXML(p string, v any) error {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pathDir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, append([]byte(xml.Header), append(b, '\n')...), 0o644)
+}
+
+// SARIF 2.1.0 (только то, что нужно для code scanning).
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+const (
+	ruleUnexpectedChange = "unexpected-change"
+	ruleForbiddenImport  = "forbidden-import"
+	ruleDiffBudget       = "diff-budget"
+)
+
+func toSARIF(rep Report) sarifLog {
+	results := []sarifResult{}
+	for _, d := range rep.Decisions {
+		if d.Allowed {
+			continue
+		}
+		results = append(results, sarifResult{
+			RuleID:    ruleUnexpectedChange,
+			Level:     "error",
+			Message:   sarifMessage{Text: fmt.Sprintf("%s %s: %s", d.Status, d.Path, d.Rule)},
+			Locations: []sarifLocation{sarifLocationFor(d.Path, 0)},
+		})
+	}
+	for _, v := range rep.ImportViolations {
+		results = append(results, sarifResult{
+			RuleID:    ruleForbiddenImport,
+			Level:     "error",
+			Message:   sarifMessage{Text: v.Reason},
+			Locations: []sarifLocation{sarifLocationFor(v.File, v.Line)},
+		})
+	}
+	for _, st := range rep.Files {
+		for _, e := range st.Exceeded {
+			results = append(results, sarifResult{
+				RuleID:    ruleDiffBudget,
+				Level:     "error",
+				Message:   sarifMessage{Text: fmt.Sprintf("%s (+%d -%d): %s", st.Path, st.Added, st.Removed, e)},
+				Locations: []sarifLocation{sarifLocationFor(st.Path, 0)},
+			})
+		}
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: "change_check",
+				Rules: []sarifRule{
+					{ID: ruleUnexpectedChange, ShortDescription: sarifMessage{Text: "Change is not allowed by the diff policy"}},
+					{ID: ruleForbiddenImport, ShortDescription: sarifMessage{Text: "Import is not allowed by the import policy"}},
+					{ID: ruleDiffBudget, ShortDescription: sarifMessage{Text: "Change exceeds the diff budget"}},
+				},
+			}},
+			Results: results,
+		}},
+	}
+}
+
+func sarifLocationFor(p string, line int) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: p},
+	}}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return loc
+}
+
+// JUnit XML: один testcase на каждый изменённый путь.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func toJUnit(rep Report) junitTestSuites {
+	problems := map[string][]string{}
+	for _, d := range rep.Decisions {
+		if !d.Allowed {
+			problems[d.Path] = append(problems[d.Path], fmt.Sprintf("%s: %s", d.Status, d.Rule))
+		}
+	}
+	for _, v := range rep.ImportViolations {
+		problems[v.File] = append(problems[v.File], fmt.Sprintf("%s:%d: %s", v.File, v.Line, v.Reason))
+	}
+	for _, st := range rep.Files {
+		for _, e := range st.Exceeded {
+			problems[st.Path] = append(problems[st.Path], fmt.Sprintf("+%d -%d: %s", st.Added, st.Removed, e))
+		}
+	}
+
+	suite := junitTestSuite{Name: "change_check", Timestamp: rep.CheckedAt}
+	for _, p := range rep.ChangedPaths {
+		tc := junitTestCase{Name: p, ClassName: "change_check"}
+		if msgs := problems[p]; len(msgs) > 0 {
+			tc.Failure = &junitFailure{
+				Message: msgs[0],
+				Type:    "policy",
+				Text:    strings.Join(msgs, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	return junitTestSuites{
+		Name:     "change_check",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+}
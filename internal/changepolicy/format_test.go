@@ -0,0 +1,143 @@
+package changepolicy
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testReport() Report {
+	return Report{
+		CheckedAt:    "2026-01-02T03:04:05Z",
+		ChangedPaths: []string{"tasks/task_01/solution.go", "tasks/task_01/solution_test.go", "README.md"},
+		Decisions: []Decision{
+			{Path: "tasks/task_01/solution.go", Status: "M", Allowed: true, Rule: "allow"},
+			{Path: "tasks/task_01/solution_test.go", Status: "M", Rule: "deny tests"},
+			{Path: "README.md", Status: "M", Allowed: true, Rule: "allow"},
+		},
+		ImportViolations: []ImportViolation{
+			{File: "tasks/task_01/solution.go", Line: 4, Import: "os/exec", Reason: `import "os/exec" is denied by "os/exec"`},
+		},
+		Files: []FileStat{
+			{Path: "tasks/task_01/solution.go", Added: 300, Exceeded: []string{"added 300 > 200"}},
+		},
+	}
+}
+
+func TestWriteReportSARIF(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "out.sarif")
+	if err := WriteReport(p, "sarif", testReport()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct{ URI string }
+			Region           *struct{ StartLine int }
+		}
+	}
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []location
+			}
+		}
+	}
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "change_check" {
+		t.Fatalf("sarif header = version %q, %d runs; want 2.1.0, 1 run of change_check", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	ruleIDs := map[string]bool{}
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs[r.ID] = true
+	}
+
+	type result struct {
+		rule, uri string
+		line      int
+	}
+	var got []result
+	for _, r := range run.Results {
+		if !ruleIDs[r.RuleID] {
+			t.Errorf("result ruleId %q is not declared in driver.rules", r.RuleID)
+		}
+		if r.Level != "error" || len(r.Locations) != 1 {
+			t.Fatalf("result %s: level %q, %d locations; want error, 1", r.RuleID, r.Level, len(r.Locations))
+		}
+		loc := r.Locations[0].PhysicalLocation
+		res := result{rule: r.RuleID, uri: loc.ArtifactLocation.URI}
+		if loc.Region != nil {
+			res.line = loc.Region.StartLine
+		}
+		got = append(got, res)
+	}
+	want := []result{
+		{ruleUnexpectedChange, "tasks/task_01/solution_test.go", 0},
+		{ruleForbiddenImport, "tasks/task_01/solution.go", 4},
+		{ruleDiffBudget, "tasks/task_01/solution.go", 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sarif results =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestWriteReportJUnit(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "out.xml")
+	if err := WriteReport(p, "junit", testReport()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tests != 3 || got.Failures != 2 || len(got.Suites) != 1 {
+		t.Fatalf("testsuites tests=%d failures=%d suites=%d; want 3, 2, 1", got.Tests, got.Failures, len(got.Suites))
+	}
+	suite := got.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 || suite.Timestamp != "2026-01-02T03:04:05Z" {
+		t.Fatalf("testsuite tests=%d failures=%d timestamp=%q; want 3, 2, 2026-01-02T03:04:05Z", suite.Tests, suite.Failures, suite.Timestamp)
+	}
+
+	failures := map[string]string{}
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil {
+			failures[tc.Name] = tc.Failure.Text
+		}
+	}
+	want := map[string]string{
+		"tasks/task_01/solution.go": "tasks/task_01/solution.go:4: import \"os/exec\" is denied by \"os/exec\"\n" +
+			"+300 -0: added 300 > 200",
+		"tasks/task_01/solution_test.go": "M: deny tests",
+	}
+	if !reflect.DeepEqual(failures, want) {
+		t.Fatalf("failures =\n%q\nwant\n%q", failures, want)
+	}
+}
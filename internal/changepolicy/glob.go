@@ -0,0 +1,188 @@
+package changepolicy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GlobToRegex переводит шаблон в регулярку с семантикой .gitignore/CODEOWNERS:
+//   - шаблон без "/" в начале или середине совпадает на любой глубине ("*.go");
+//   - ведущий "/" привязывает шаблон к корню;
+//   - завершающий "/" совпадает только с каталогом (то есть со всем, что внутри);
+//   - совпадение с каталогом означает совпадение со всеми путями под ним;
+//   - "**/" — ноль или больше каталогов, "/**" — всё внутри, другие "**" — как "*";
+//   - "*" и "?" не пересекают "/", "[abc]", "[!a-z]", "[[:digit:]]" — классы символов;
+//   - "{a,b}" — альтернативы (расширение CODEOWNERS/shell), "\x" — буквальный x.
+func GlobToRegex(pat string) (*regexp.Regexp, error) {
+	dirOnly := false
+	if strings.HasSuffix(pat, "/") && !strings.HasSuffix(pat, `\/`) {
+		dirOnly = true
+		pat = strings.TrimRight(pat, "/")
+	}
+	anchored := strings.Contains(pat, "/")
+	pat = strings.TrimPrefix(pat, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	b.WriteString(translateGlob(pat))
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// translateGlob переводит тело шаблона без якорей.
+func translateGlob(pat string) string {
+	var b strings.Builder
+	for i := 0; i < len(pat); i++ {
+		ch := pat[i]
+		switch ch {
+		case '\\':
+			if i+1 < len(pat) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pat[i : i+1]))
+			} else {
+				b.WriteString(`\\`)
+			}
+
+		case '*':
+			j := i
+			for j < len(pat) && pat[j] == '*' {
+				j++
+			}
+			atStart := i == 0 || pat[i-1] == '/'
+			atEnd := j == len(pat) || pat[j] == '/'
+			switch {
+			case j-i >= 2 && atStart && j == len(pat):
+				// "dir/**" или просто "**"
+				b.WriteString(".*")
+			case j-i >= 2 && atStart && atEnd:
+				// "**/" — ноль или больше каталогов
+				b.WriteString("(?:.*/)?")
+				j++
+			default:
+				b.WriteString(`[^/]*`)
+			}
+			i = j - 1
+
+		case '?':
+			b.WriteString(`[^/]`)
+
+		case '[':
+			class, n := translateClass(pat[i:])
+			if n == 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i += n - 1
+
+		case '{':
+			alts, n := splitBraces(pat[i:])
+			if n == 0 {
+				b.WriteString(`\{`)
+				continue
+			}
+			b.WriteString("(?:")
+			for k, alt := range alts {
+				if k > 0 {
+					b.WriteString("|")
+				}
+				b.WriteString(translateGlob(alt))
+			}
+			b.WriteString(")")
+			i += n - 1
+
+		default:
+			b.WriteString(regexp.QuoteMeta(pat[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// translateClass разбирает "[...]" в начале s. Возвращает класс регулярки
+// и длину шаблона; n == 0, если скобка не закрыта.
+func translateClass(s string) (class string, n int) {
+	i := 1
+	negate := false
+	if i < len(s) && (s[i] == '!' || s[i] == '^') {
+		negate = true
+		i++
+	}
+
+	var b strings.Builder
+	first := true
+	for ; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == ']' && !first:
+			if b.Len() == 0 {
+				return "", 0
+			}
+			if negate {
+				return "[^/" + b.String() + "]", i + 1
+			}
+			return "[" + b.String() + "]", i + 1
+		case ch == '[' && i+1 < len(s) && s[i+1] == ':':
+			end := strings.Index(s[i+2:], ":]")
+			if end < 0 {
+				b.WriteString(`\[`)
+				break
+			}
+			b.WriteString(s[i : i+2+end+2])
+			i += 2 + end + 1
+		case ch == '\\' && i+1 < len(s):
+			i++
+			b.WriteString(escapeClassChar(s[i]))
+		case ch == '-' && !first && i+1 < len(s) && s[i+1] != ']':
+			b.WriteString("-")
+		case ch == '/':
+			// "/" никогда не совпадает с классом
+		default:
+			b.WriteString(escapeClassChar(ch))
+		}
+		first = false
+	}
+	return "", 0
+}
+
+func escapeClassChar(ch byte) string {
+	if strings.IndexByte(`\]-[^`, ch) >= 0 {
+		return `\` + string(ch)
+	}
+	return string(ch)
+}
+
+// splitBraces разбирает "{a,b,...}" в начале s с учётом вложенности.
+// n == 0, если скобка не закрыта или внутри нет запятой верхнего уровня.
+func splitBraces(s string) (alts []string, n int) {
+	depth := 0
+	start := 1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				if len(alts) == 0 {
+					return nil, 0
+				}
+				return append(alts, s[start:i]), i + 1
+			}
+		case ',':
+			if depth == 1 {
+				alts = append(alts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return nil, 0
+}
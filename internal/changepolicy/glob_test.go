@@ -0,0 +1,85 @@
+package changepolicy
+
+import "testing"
+
+// Ожидания для шаблонов без фигурных скобок сверены с `git check-ignore --no-index`.
+func TestGlobToRegexConformance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"tasks/task_01/solution.go", "tasks/task_01/solution.go", true},
+		{"tasks/task_01/solution.go", "tasks/task_01/solution_test.go", false},
+		{"tasks/task_01/solution.go", "x/tasks/task_01/solution.go", false},
+		{"tasks/*/solution.go", "tasks/task_01/solution.go", true},
+		{"tasks/*/solution.go", "tasks/a/b/solution.go", false},
+		{"tasks/**/solution.go", "tasks/solution.go", true},
+		{"tasks/**/solution.go", "tasks/a/b/solution.go", true},
+		{"**/solution.go", "solution.go", true},
+		{"**/solution.go", "tasks/task_01/solution.go", true},
+		{"*.go", "main.go", true},
+		{"*.go", "tasks/task_01/solution.go", true},
+		{"*.go", "main.go.orig", false},
+		{"/main.go", "main.go", true},
+		{"/main.go", "cmd/main.go", false},
+		{"main.go", "cmd/main.go", true},
+		{".git/**", ".git/HEAD", true},
+		{".git/**", ".git/refs/heads/main", true},
+		{".git/**", ".git", false},
+		{".git/**", "sub/.git/HEAD", false},
+		{"docs/", "docs/a.md", true},
+		{"docs/", "docs", false},
+		{"docs/", "x/docs/a.md", true},
+		{"docs", "x/docs/a.md", true},
+		{"tasks/task_01", "tasks/task_01/solution.go", true},
+		{"tasks/task_0?/solution.go", "tasks/task_01/solution.go", true},
+		{"tasks/task_0?/solution.go", "tasks/task_10/solution.go", false},
+		{"tasks/task_0?/solution.go", "tasks/task_0/solution.go", false},
+		{"tasks/task_[0-9][0-9]/solution.go", "tasks/task_12/solution.go", true},
+		{"tasks/task_[0-9][0-9]/solution.go", "tasks/task_1a/solution.go", false},
+		{"tasks/task_[!0]?/solution.go", "tasks/task_12/solution.go", true},
+		{"tasks/task_[!0]?/solution.go", "tasks/task_02/solution.go", false},
+		{"tasks/task_[^0]?/solution.go", "tasks/task_02/solution.go", false},
+		{"file[[:digit:]].txt", "file7.txt", true},
+		{"file[[:digit:]].txt", "filex.txt", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/b/c", true},
+		{"a/**/b", "ab", false},
+		{"a**b", "axyb", true},
+		{"a**b", "ax/yb", false},
+		{`\*.go`, "*.go", true},
+		{`\*.go`, "main.go", false},
+		{`foo\[1\]`, "foo[1]", true},
+		{`foo\[1\]`, "foo1", false},
+		{"foo?bar", "foo/bar", false},
+		{"foo*bar", "foo/bar", false},
+		{"[ab]", "x/a", true},
+		{"Tasks/task_01/solution.go", "tasks/task_01/solution.go", false},
+		{"**", "anything/at/all", true},
+		{"a/**", "a", false},
+
+		// фигурные скобки (как в CODEOWNERS/shell)
+		{"tasks/task_{01,02}/solution.go", "tasks/task_01/solution.go", true},
+		{"tasks/task_{01,02}/solution.go", "tasks/task_02/solution.go", true},
+		{"tasks/task_{01,02}/solution.go", "tasks/task_03/solution.go", false},
+		{"*.{go,md}", "tasks/task_01/README.md", true},
+		{"*.{go,md}", "tasks/task_01/go.sum", false},
+		{"a{b,c{d,e}}f", "acef", true},
+		{"a{b,c{d,e}}f", "acf", false},
+		{"{single}", "{single}", true},
+		{"{a,b", "{a,b", true},
+	}
+	for _, tt := range tests {
+		re, err := GlobToRegex(tt.pattern)
+		if err != nil {
+			t.Fatalf("GlobToRegex(%q): %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("GlobToRegex(%q).MatchString(%q) = %v; want %v (re %s)", tt.pattern, tt.path, got, tt.want, re)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package changepolicy
+
+import (
+	"industry_backend_go/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestImportViolation(t *testing.T) {
+	t.Parallel()
+
+	no := false
+	stdlib := config.ImportPolicy{Deny: []string{"os/exec", "net/..."}, Allow: []string{"golang.org/x/exp/..."}}
+	thirdParty := config.ImportPolicy{StdlibOnly: &no, Deny: []string{"unsafe"}}
+	tests := []struct {
+		imp    string
+		policy config.ImportPolicy
+		want   string
+	}{
+		{"fmt", stdlib, ""},
+		{"os/exec", stdlib, `import "os/exec" is denied by "os/exec"`},
+		{"os", stdlib, ""},
+		{"net", stdlib, `import "net" is denied by "net/..."`},
+		{"net/http/httptest", stdlib, `import "net/http/httptest" is denied by "net/..."`},
+		{"network", stdlib, ""},
+		{"golang.org/x/exp/slices", stdlib, ""},
+		{"golang.org/x/sync/errgroup", stdlib, `import "golang.org/x/sync/errgroup" is not in stdlib`},
+		{"m", stdlib, `import "m" is a module package, only stdlib is allowed`},
+		{"m/internal/config", stdlib, `import "m/internal/config" is a module package, only stdlib is allowed`},
+		{"mx/y", stdlib, ""}, // не модуль и без точки — эвристика go считает stdlib
+		{"C", stdlib, `import "C" (cgo) is not in stdlib`},
+		{"C", thirdParty, ""},
+		{"m/internal/config", thirdParty, ""},
+		{"github.com/a/b", thirdParty, ""},
+		{"unsafe", thirdParty, `import "unsafe" is denied by "unsafe"`},
+	}
+	for _, tt := range tests {
+		if got := importViolation(tt.imp, "m", tt.policy); got != tt.want {
+			t.Errorf("importViolation(%q, stdlib_only %v) = %q; want %q", tt.imp, tt.policy.IsStdlibOnly(), got, tt.want)
+		}
+	}
+}
+
+func TestIsStdlibImport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		imp  string
+		want bool
+	}{
+		{"fmt", true},
+		{"net/http", true},
+		{"github.com/a/b", false},
+		{"example.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isStdlibImport(tt.imp); got != tt.want {
+			t.Errorf("isStdlibImport(%q) = %v; want %v", tt.imp, got, tt.want)
+		}
+	}
+}
+
+func TestCheckImports(t *testing.T) {
+	t.Parallel()
+
+	root := writeTree(t, map[string]string{
+		"go.mod":                    "module m\n\ngo 1.22\n",
+		"tasks/task_01/solution.go": "package task01\n\nimport (\n\t\"fmt\"\n\t\"os/exec\"\n)\n\nfunc F() { fmt.Println(exec.Command) }\n",
+		// синтаксическая ошибка в импортах: проверяем то, что разобралось
+		"tasks/task_02/solution.go": "package task02\n\nimport (\n\t\"m/internal/config\"\n\t\"fmt\n)\n",
+		"tasks/task_03/solution.go": "package task03\n\n// #include <stdio.h>\nimport \"C\"\n",
+		"tasks/task_04/new.go":      "package task04\n\nimport \"unsafe\"\n",
+		"tasks/task_05/denied.go":   "package task05\n\nimport \"unsafe\"\n",
+		"docs/notes.md":             "import \"unsafe\"\n",
+	})
+	decisions := []Decision{
+		{Path: "tasks/task_01/solution.go", Status: "M", Allowed: true},
+		{Path: "tasks/task_01/solution.go", Status: "M", Allowed: true}, // повтор не даёт дублей
+		{Path: "tasks/task_02/solution.go", Status: "A", Allowed: true},
+		{Path: "tasks/task_03/solution.go", Status: "M", Allowed: true},
+		{Path: "tasks/task_04/old.go", Status: "R100", Allowed: true}, // старое имя rename — файла нет
+		{Path: "tasks/task_04/new.go", Status: "R100", Allowed: true},
+		{Path: "tasks/task_05/denied.go", Status: "M"},              // запрещённый файл и так в отчёте
+		{Path: "tasks/task_06/gone.go", Status: "D", Allowed: true}, // удалён
+		{Path: "docs/notes.md", Status: "M", Allowed: true},         // не .go
+	}
+
+	got, err := checkImports(root, config.ImportPolicy{Deny: []string{"unsafe", "os/exec"}}, decisions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ImportViolation{
+		{File: "tasks/task_01/solution.go", Line: 5, Import: "os/exec"},
+		{File: "tasks/task_02/solution.go", Line: 4, Import: "m/internal/config"},
+		{File: "tasks/task_03/solution.go", Line: 4, Import: "C"},
+		{File: "tasks/task_04/new.go", Line: 3, Import: "unsafe"},
+	}
+	for i := range got {
+		got[i].Reason = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("checkImports() =\n%+v\nwant\n%+v", got, want)
+	}
+}
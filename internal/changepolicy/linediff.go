@@ -0,0 +1,144 @@
+package changepolicy
+
+import "bytes"
+
+// maxDiffEdits ограничивает глубину поиска Myers: дальше считаем,
+// что файл заменён целиком (для статистики и патчей этого достаточно).
+const maxDiffEdits = 2000
+
+type diffOp struct {
+	kind byte // ' ' — общая строка, '-' — удалена, '+' — добавлена
+	line string
+}
+
+// diffLines строит построчный diff a → b: общие начало и конец отрезаются,
+// середина сравнивается алгоритмом Myers.
+func diffLines(a, b []string) []diffOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b)-pre-suf)
+	for _, l := range a[:pre] {
+		ops = append(ops, diffOp{kind: ' ', line: l})
+	}
+	ops = append(ops, myers(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, diffOp{kind: ' ', line: l})
+	}
+	return ops
+}
+
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		// сохраняем только окно k ∈ [-d-1, d+1], остальное не понадобится при обратном проходе
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrackDiff(a, b []string, trace [][]int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{kind: ' ', line: a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{kind: '+', line: b[y-1]})
+			y--
+		} else {
+			ops = append(ops, diffOp{kind: '-', line: a[x-1]})
+			x--
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a {
+		ops = append(ops, diffOp{kind: '-', line: l})
+	}
+	for _, l := range b {
+		ops = append(ops, diffOp{kind: '+', line: l})
+	}
+	return ops
+}
+
+// countLines — числа добавленных и удалённых строк, как в git diff --numstat.
+func countLines(a, b []byte) (added, removed int) {
+	for _, op := range diffLines(textLines(a), textLines(b)) {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func textLines(b []byte) []string {
+	raw := splitLines(b)
+	out := make([]string, len(raw))
+	for i, l := range raw {
+		out[i] = string(l)
+	}
+	return out
+}
+
+// isBinary — та же эвристика, что у git: NUL в первых 8000 байтах.
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b[:min(len(b), 8000)], 0) >= 0
+}
@@ -0,0 +1,173 @@
+package changepolicy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxMarkdownRows ограничивает таблицу путей (лимит $GITHUB_STEP_SUMMARY — 1 MiB).
+const maxMarkdownRows = 300
+
+// RenderMarkdown пишет сводку в GitHub-flavored markdown для $GITHUB_STEP_SUMMARY
+// или комментария к PR. baselineRef подставляется в подсказки вида
+// `git checkout <baselineRef> -- path`.
+func RenderMarkdown(w io.Writer, rep Report, baselineRef string) error {
+	var b strings.Builder
+
+	if rep.OK {
+		b.WriteString("## ✅ Change policy: passed\n\n")
+	} else {
+		b.WriteString("## ❌ Change policy: failed\n\n")
+	}
+	if rep.Error != "" {
+		fmt.Fprintf(&b, "Policy error: %s\n\n", mdText(rep.Error))
+	}
+	fmt.Fprintf(&b, "Changed files: **%d**, unexpected: **%d**, forbidden imports: **%d**, budgets exceeded: **%d**.\n\n",
+		len(rep.ChangedPaths), len(rep.Unexpected), len(rep.ImportViolations), rep.BudgetViolations())
+
+	if len(rep.Decisions) > 0 {
+		b.WriteString("| Path | Status | Rule | Result |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for i, d := range rep.Decisions {
+			if i == maxMarkdownRows {
+				fmt.Fprintf(&b, "\n_…and %d more paths, see the JSON report._\n", len(rep.Decisions)-i)
+				break
+			}
+			result := "✅ allowed"
+			if !d.Allowed {
+				result = "❌ not allowed"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", mdCode(d.Path), mdText(d.Status), mdText(d.Rule), result)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(rep.ImportViolations) > 0 {
+		b.WriteString("### Forbidden imports\n\n")
+		b.WriteString("| File | Line | Import | Reason |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, v := range rep.ImportViolations {
+			fmt.Fprintf(&b, "| %s | %d | %s | %s |\n", mdCode(v.File), v.Line, mdCode(v.Import), mdText(v.Reason))
+		}
+		b.WriteString("\n")
+	}
+
+	if rep.BudgetViolations() > 0 {
+		b.WriteString("### Diff budgets\n\n")
+		b.WriteString("| File | Added | Removed | Exceeded |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, st := range rep.Files {
+			if len(st.Exceeded) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", mdCode(st.Path), st.Added, st.Removed, mdText(strings.Join(st.Exceeded, "; ")))
+		}
+		b.WriteString("\n")
+	}
+
+	if hints := remediationHints(rep, baselineRef); len(hints) > 0 {
+		b.WriteString("### How to fix\n\n")
+		for _, h := range hints {
+			b.WriteString("- " + h + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// remediationHints подсказывает, как вернуть каждое недопустимое изменение.
+func remediationHints(rep Report, baselineRef string) []string {
+	var hints []string
+	seen := map[string]struct{}{}
+	add := func(h string) {
+		if _, ok := seen[h]; !ok {
+			seen[h] = struct{}{}
+			hints = append(hints, h)
+		}
+	}
+	for _, ch := range rep.UnexpectedBySt {
+		p := changeKey(ch)
+		switch ch.Kind {
+		case kindMode:
+			bit := "-x"
+			if ch.OldMode == modeExec {
+				bit = "+x"
+			}
+			add(fmt.Sprintf("restore file mode of %s: %s", mdSpan(p), mdSpan("git update-index --chmod="+bit+" "+shellQuote(p))))
+		case kindGitlink:
+			add(fmt.Sprintf("remove submodule %s: %s", mdSpan(p), mdSpan("git rm --cached "+shellQuote(p))))
+		}
+	}
+	for _, op := range revertOps(rep) {
+		switch {
+		case op.kind == kindMode || op.kind == kindGitlink:
+		case op.restore:
+			add(fmt.Sprintf("restore %s: %s", mdSpan(op.path), mdSpan("git checkout "+shellQuote(baselineRef)+" -- "+shellQuote(op.path))))
+		default:
+			add(fmt.Sprintf("remove %s: %s", mdSpan(op.path), mdSpan("git rm -- "+shellQuote(op.path))))
+		}
+	}
+
+	for _, v := range rep.ImportViolations {
+		add(fmt.Sprintf("remove import %s from %s (line %d)", mdSpan(v.Import), mdSpan(v.File), v.Line))
+	}
+	for _, st := range rep.Files {
+		if len(st.Exceeded) > 0 {
+			add(fmt.Sprintf("reduce the change in %s (now +%d -%d)", mdSpan(st.Path), st.Added, st.Removed))
+		}
+	}
+	return hints
+}
+
+// mdEscaper экранирует разметку markdown в обычном тексте; | — для ячеек таблиц.
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "~", `\~`, "|", `\|`, "\r", "", "\n", " ",
+)
+
+func mdText(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdCode — code span для ячейки таблицы: внутри таблицы GFM требует \| даже в коде.
+func mdCode(s string) string {
+	return mdSpan(strings.ReplaceAll(s, "|", `\|`))
+}
+
+// mdSpan — code span вне таблиц. Ограничитель длиннее любой серии ` внутри,
+// пробелы по краям — если код начинается или кончается на `.
+func mdSpan(s string) string {
+	s = strings.NewReplacer("\r", "", "\n", " ").Replace(s)
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// shellQuote квотирует путь для sh, если в нём есть что-то кроме безопасных символов.
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-+", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
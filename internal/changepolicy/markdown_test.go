@@ -0,0 +1,80 @@
+package changepolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownEscaping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, in, want string
+		f              func(string) string
+	}{
+		{"mdText", "a|b", `a\|b`, mdText},
+		{"mdText", `deny "tasks/**/*_test.go" (rules[1])`, `deny "tasks/\*\*/\*\_test.go" (rules\[1\])`, mdText},
+		{"mdText", "line1\r\nline2 <b>", `line1 line2 \<b\>`, mdText},
+		{"mdCode", "tasks/a|b.go", "`tasks/a\\|b.go`", mdCode},
+		{"mdCode", "a`b.go", "``a`b.go``", mdCode},
+		{"mdSpan", "a|b.go", "`a|b.go`", mdSpan},
+		{"mdSpan", "a``b`", "``` a``b` ```", mdSpan},
+		{"mdSpan", "`x", "`` `x ``", mdSpan},
+		{"mdSpan", "a\nb", "`a b`", mdSpan},
+	}
+	for _, tt := range tests {
+		if got := tt.f(tt.in); got != tt.want {
+			t.Errorf("%s(%q) = %q; want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	rep := Report{
+		ChangedPaths: []string{"tasks/task_01/solution_test.go", "tasks/a|b`c.go", "run.sh", "vendor/lib", "tasks/task_01/solution.go"},
+		Unexpected:   []string{"tasks/task_01/solution_test.go", "tasks/a|b`c.go", "run.sh", "vendor/lib"},
+		UnexpectedBySt: []Change{
+			{Status: "M", Path: "tasks/task_01/solution_test.go"},
+			{Status: "A", Path: "tasks/a|b`c.go"},
+			{Status: "M", Path: "run.sh", Kind: kindMode, OldMode: modeFile, NewMode: modeExec},
+			{Status: "A", Path: "vendor/lib", Kind: kindGitlink},
+		},
+		Decisions: []Decision{
+			{Path: "tasks/task_01/solution_test.go", Status: "M", Rule: `deny "tasks/**/*_test.go" (rules[1])`},
+			{Path: "tasks/a|b`c.go", Status: "A", Rule: "no rule matched (default deny)"},
+			{Path: "tasks/task_01/solution.go", Status: "M", Allowed: true, Rule: `allow "tasks/*/solution.go" (allow_list[0])`},
+		},
+		ImportViolations: []ImportViolation{
+			{File: "tasks/task_01/solution.go", Line: 3, Import: "os/exec", Reason: `import "os/exec" is denied by "os/exec"`},
+		},
+		Files: []FileStat{{Path: "tasks/task_01/solution.go", Added: 500, Exceeded: []string{"added 500 > 200"}}},
+	}
+
+	var b strings.Builder
+	if err := RenderMarkdown(&b, rep, "origin/main"); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		"## ❌ Change policy: failed\n",
+		"Changed files: **5**, unexpected: **4**, forbidden imports: **1**, budgets exceeded: **1**.\n",
+		"| `tasks/task_01/solution_test.go` | M | deny \"tasks/\\*\\*/\\*\\_test.go\" (rules\\[1\\]) | ❌ not allowed |\n",
+		"| ``tasks/a\\|b`c.go`` | A | no rule matched (default deny) | ❌ not allowed |\n",
+		"| `tasks/task_01/solution.go` | 3 | `os/exec` | import \"os/exec\" is denied by \"os/exec\" |\n",
+		"| `tasks/task_01/solution.go` | 500 | 0 | added 500 \\> 200 |\n",
+		"### How to fix\n\n" +
+			"- restore file mode of `run.sh`: `git update-index --chmod=-x run.sh`\n" +
+			"- remove submodule `vendor/lib`: `git rm --cached vendor/lib`\n" +
+			"- restore `tasks/task_01/solution_test.go`: `git checkout origin/main -- tasks/task_01/solution_test.go`\n" +
+			"- remove ``tasks/a|b`c.go``: ``git rm -- 'tasks/a|b`c.go'``\n" +
+			"- remove import `os/exec` from `tasks/task_01/solution.go` (line 3)\n" +
+			"- reduce the change in `tasks/task_01/solution.go` (now +500 -0)\n\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderMarkdown() output does not contain\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package changepolicy
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveMirror ищет git-репозиторий baseline: сам dir или зеркало repo внутри
+// него (dir/owner/name.git, dir/owner/name, dir/name.git). Подходят и bare,
+// и обычные клоны (тогда берётся их .git).
+func ResolveMirror(dir, repo string) (string, error) {
+	candidates := []string{dir}
+	if repo != "" {
+		candidates = append(candidates,
+			filepath.Join(dir, filepath.FromSlash(repo)+".git"),
+			filepath.Join(dir, filepath.FromSlash(repo)),
+			filepath.Join(dir, path.Base(repo)+".git"),
+		)
+	}
+	for _, c := range candidates {
+		for _, gitDir := range []string{c, filepath.Join(c, ".git")} {
+			if _, err := gitOutput(gitDir, "rev-parse", "--git-dir"); err == nil {
+				return gitDir, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no git repository for %q in %s", repo, dir)
+}
+
+// ResolveRef возвращает коммит для ref; в обычном (не bare) клоне пробует и origin/<ref>.
+func ResolveRef(gitDir, ref string) (string, error) {
+	var lastErr error
+	for _, r := range []string{ref, "origin/" + ref} {
+		out, err := gitOutput(gitDir, "rev-parse", "--verify", "--quiet", r+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), nil
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("ref %q: %w", ref, lastErr)
+}
+
+// CheckoutRef выгружает дерево коммита во временный каталог через git archive,
+// не трогая сам репозиторий (в том числе его index).
+func CheckoutRef(gitDir, commit string) (string, error) {
+	dir, err := os.MkdirTemp("", "change_check-tree-")
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "--git-dir", gitDir, "archive", "--format=tar", commit)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	extractErr := extractTar(stdout, dir)
+	// дочитываем, чтобы git не завис на записи в pipe
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+	if waitErr != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("git archive: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		os.RemoveAll(dir)
+		return "", extractErr
+	}
+	return dir, nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(hdr.Name)
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o777)
+			if err != nil {
+				return err
+			}
+			_, copyErr := io.Copy(f, tr)
+			closeErr := f.Close()
+			if copyErr != nil {
+				return copyErr
+			}
+			if closeErr != nil {
+				return closeErr
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// pax-заголовки git archive и прочее — пропускаем
+		}
+	}
+}
+
+func gitOutput(gitDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"--git-dir", gitDir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
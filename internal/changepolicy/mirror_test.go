@@ -0,0 +1,123 @@
+package changepolicy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// git запускает git в dir с детерминированным автором и без глобального конфига.
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestMirror(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmp := t.TempDir()
+	mirrors := filepath.Join(tmp, "mirrors")
+	work := writeTree(t, map[string]string{
+		"tasks/task_01/solution.go": "package task01\n",
+		"README.md":                 "v1\n",
+	})
+	git(t, work, "init", "-q", "-b", "main")
+	git(t, work, "add", "-A")
+	git(t, work, "commit", "-q", "-m", "v1")
+	v1 := git(t, work, "rev-parse", "HEAD")
+	git(t, work, "tag", "v1")
+
+	git(t, work, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("feature\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, work, "commit", "-q", "-am", "feature")
+	feature := git(t, work, "rev-parse", "HEAD")
+	git(t, work, "checkout", "-q", "main")
+
+	bare := filepath.Join(mirrors, "owner", "name.git")
+	git(t, tmp, "clone", "-q", "--mirror", work, bare)
+	clone := filepath.Join(tmp, "clone")
+	git(t, tmp, "clone", "-q", bare, clone)
+
+	t.Run("ResolveMirror", func(t *testing.T) {
+		tests := []struct {
+			dir, repo, want string
+		}{
+			{mirrors, "owner/name", bare},
+			{filepath.Join(mirrors, "owner"), "other/name", bare}, // dir/name.git
+			{bare, "", bare},
+			{clone, "", filepath.Join(clone, ".git")},
+		}
+		for _, tt := range tests {
+			got, err := ResolveMirror(tt.dir, tt.repo)
+			if err != nil || got != tt.want {
+				t.Errorf("ResolveMirror(%q, %q) = %q, %v; want %q", tt.dir, tt.repo, got, err, tt.want)
+			}
+		}
+		if got, err := ResolveMirror(mirrors, "owner/missing"); err == nil {
+			t.Errorf("ResolveMirror(missing) = %q; want error", got)
+		}
+	})
+
+	t.Run("ResolveRef", func(t *testing.T) {
+		tests := []struct {
+			gitDir, ref, want string
+		}{
+			{bare, "main", v1},
+			{bare, "v1", v1},
+			{bare, "feature", feature},
+			{bare, v1[:10], v1},
+			{filepath.Join(clone, ".git"), "feature", feature}, // только origin/feature
+		}
+		for _, tt := range tests {
+			got, err := ResolveRef(tt.gitDir, tt.ref)
+			if err != nil || got != tt.want {
+				t.Errorf("ResolveRef(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+			}
+		}
+		if got, err := ResolveRef(bare, "no-such-ref"); err == nil {
+			t.Errorf("ResolveRef(no-such-ref) = %q; want error", got)
+		}
+	})
+
+	t.Run("CheckoutRef", func(t *testing.T) {
+		dir, err := CheckoutRef(bare, feature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		for name, want := range map[string]string{
+			"tasks/task_01/solution.go": "package task01\n",
+			"README.md":                 "feature\n",
+		} {
+			b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil || string(b) != want {
+				t.Errorf("%s = %q, %v; want %q", name, b, err, want)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+			t.Errorf("checkout contains .git: %v", err)
+		}
+		if _, err := CheckoutRef(bare, "0000000000000000000000000000000000000000"); err == nil {
+			t.Error("CheckoutRef(unknown commit) = nil error; want error")
+		}
+	})
+}
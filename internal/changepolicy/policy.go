@@ -0,0 +1,335 @@
+// Package changepolicy проверяет изменения форка относительно baseline по
+// политике из config.json: правила allow/deny, импорты, бюджеты размера diff
+// и особые изменения (симлинки, подмодули, режимы файлов).
+package changepolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"industry_backend_go/internal/config"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Change struct {
+	Status  string `json:"status"`
+	Path    string `json:"path,omitempty"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	OldMode string `json:"old_mode,omitempty"`
+	NewMode string `json:"new_mode,omitempty"`
+	Target  string `json:"target,omitempty"` // цель симлинка
+	Kind    string `json:"kind,omitempty"`   // type|gitlink|symlink|mode
+	Reason  string `json:"reason,omitempty"`
+	Raw     string `json:"raw"`
+}
+
+func (ch Change) paths() []string {
+	var out []string
+	for _, p := range []string{ch.Path, ch.From, ch.To} {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+type Report struct {
+	OK             bool              `json:"ok"`
+	CheckedAt      string            `json:"checked_at"`
+	DiffFile       string            `json:"diff_file"`
+	Baseline       string            `json:"baseline,omitempty"`
+	BaselineRef    string            `json:"baseline_ref,omitempty"`
+	BaselineCommit string            `json:"baseline_commit,omitempty"`
+	Current        string            `json:"current,omitempty"`
+	ConfigFile     string            `json:"config_file"`
+	AllowList      []string          `json:"allow_list"`
+	Rules          []config.DiffRule `json:"rules,omitempty"`
+	ChangedPaths   []string          `json:"changed_paths"`
+	Unexpected     []string          `json:"unexpected"`
+	UnexpectedBySt []Change          `json:"unexpected_by_status,omitempty"`
+	Decisions      []Decision        `json:"decisions,omitempty"`
+
+	ImportViolations []ImportViolation `json:"import_violations,omitempty"`
+	Files            []FileStat        `json:"files,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func (r *Report) updateOK() {
+	r.OK = r.Error == "" && len(r.Unexpected) == 0 && len(r.ImportViolations) == 0 && r.BudgetViolations() == 0
+}
+
+// Policy — скомпилированная политика изменений.
+type Policy struct {
+	cfg      config.Config
+	matchers []matcher
+	budgets  []budget
+}
+
+func New(cfg config.Config) (*Policy, error) {
+	matchers, err := compileAllowList(cfg.Diff.AllowList, cfg.Diff.Rules)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := compileBudgets(cfg.Diff.Budgets)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{cfg: cfg, matchers: matchers, budgets: budgets}, nil
+}
+
+// Evaluate проверяет изменения по правилам конфига. Ошибка в конфиге
+// возвращается в Report.Error (OK при этом false).
+func Evaluate(cfg config.Config, changes []Change) Report {
+	p, err := New(cfg)
+	if err != nil {
+		rep := Report{ChangedPaths: []string{}, Unexpected: []string{}, Error: err.Error()}
+		rep.updateOK()
+		return rep
+	}
+	return p.Evaluate(changes)
+}
+
+// Decide возвращает решение для одного пути с указанным статусом git.
+// Путь сравнивается как есть, как в Evaluate.
+func (p *Policy) Decide(path, status string) Decision {
+	return decide(path, status, p.matchers)
+}
+
+// HasBudgets сообщает, заданы ли в конфиге бюджеты размера diff.
+func (p *Policy) HasBudgets() bool {
+	return len(p.budgets) > 0
+}
+
+// Evaluate проверяет каждое изменение по правилам. Для rename/copy проверяются
+// оба пути со статусом изменения. Особые изменения (симлинки, подмодули,
+// смена типа или режима) отклоняются даже на разрешённых путях.
+// Пути сравниваются как есть: "a/tasks/..." или путь с пробелом на конце —
+// другой файл, а не tasks/...; чистить пути — дело того, кто их читает.
+func (p *Policy) Evaluate(changes []Change) Report {
+	matchers := p.matchers
+	changedSet := map[string]struct{}{}
+	unexpectedSet := map[string]struct{}{}
+	rep := Report{
+		ChangedPaths: []string{},
+		Unexpected:   []string{},
+	}
+
+	for _, ch := range changes {
+		ch.Kind = classifyChange(ch)
+		ch.Reason = specialReason(ch, matchers)
+		key := changeKey(ch)
+
+		bad := false
+		for _, cp := range ch.paths() {
+			if _, ok := changedSet[cp]; !ok {
+				changedSet[cp] = struct{}{}
+				rep.ChangedPaths = append(rep.ChangedPaths, cp)
+			}
+
+			d := decide(cp, ch.Status, matchers)
+			if d.Allowed && ch.Reason != "" && cp == key {
+				d.Allowed = false
+				d.Rule = ch.Reason
+			}
+			rep.Decisions = append(rep.Decisions, d)
+			if d.Allowed {
+				continue
+			}
+			bad = true
+			if _, ok := unexpectedSet[cp]; !ok {
+				unexpectedSet[cp] = struct{}{}
+				rep.Unexpected = append(rep.Unexpected, cp)
+			}
+		}
+		// детализируем unexpectedBySt (чтобы было понятно, что именно случилось)
+		if bad {
+			rep.UnexpectedBySt = append(rep.UnexpectedBySt, ch)
+		}
+	}
+
+	sort.Strings(rep.ChangedPaths)
+	sort.Strings(rep.Unexpected)
+	rep.AllowList = p.cfg.Diff.AllowList
+	rep.Rules = p.cfg.Diff.Rules
+	rep.updateOK()
+	return rep
+}
+
+func writeJSON(p string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pathDir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o644)
+}
+
+func pathDir(p string) string {
+	// маленький helper, чтобы не тянуть filepath (нам нужны / пути в репо)
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return "."
+	}
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+type matcher struct {
+	source  string // откуда правило: allow_list[i] или rules[i]
+	pattern string
+	deny    bool
+	status  string // допустимые статусы git (лидирующие буквы), пусто = любой
+	re      *regexp.Regexp
+}
+
+func (m matcher) String() string {
+	action := "allow"
+	if m.deny {
+		action = "deny"
+	}
+	s := fmt.Sprintf("%s %q", action, m.pattern)
+	if m.status != "" {
+		s += " [status " + m.status + "]"
+	}
+	return s + " (" + m.source + ")"
+}
+
+func (m matcher) appliesTo(status string) bool {
+	if m.status == "" {
+		return true
+	}
+	lead := statusLead(status)
+	return lead != "" && lead != "?" && strings.Contains(m.status, lead)
+}
+
+// Decision объясняет, почему путь разрешён или запрещён.
+type Decision struct {
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	Allowed bool   `json:"allowed"`
+	Rule    string `json:"rule"`
+}
+
+// compileAllowList собирает упорядоченный список правил: сначала allow_list
+// ("!pattern" = deny), затем diff.rules. При проверке побеждает последнее совпавшее.
+func compileAllowList(allowList []string, rules []config.DiffRule) ([]matcher, error) {
+	out := make([]matcher, 0, len(allowList)+len(rules))
+	add := func(source, pat string, deny bool, status string) error {
+		pat = strings.TrimSpace(pat)
+		if strings.HasPrefix(pat, "!") {
+			pat = strings.TrimSpace(pat[1:])
+			deny = !deny
+		}
+		if pat == "" {
+			return nil
+		}
+		re, err := GlobToRegex(pat)
+		if err != nil {
+			return fmt.Errorf("%s: pattern %q: %w", source, pat, err)
+		}
+		out = append(out, matcher{source: source, pattern: pat, deny: deny, status: status, re: re})
+		return nil
+	}
+
+	for i, pat := range allowList {
+		if err := add(fmt.Sprintf("allow_list[%d]", i), pat, false, ""); err != nil {
+			return nil, err
+		}
+	}
+	for i, r := range rules {
+		source := fmt.Sprintf("rules[%d]", i)
+		var deny bool
+		switch strings.ToLower(strings.TrimSpace(r.Action)) {
+		case "", "allow":
+		case "deny":
+			deny = true
+		default:
+			return nil, fmt.Errorf("%s: unknown action %q", source, r.Action)
+		}
+		status := strings.ToUpper(strings.TrimSpace(r.Status))
+		for _, c := range status {
+			if !strings.ContainsRune("AMDRCT", c) {
+				return nil, fmt.Errorf("%s: unknown status %q", source, string(c))
+			}
+		}
+		if err := add(source, r.Pattern, deny, status); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// decide прогоняет путь через все правила; результат определяет последнее совпавшее.
+// Если ничего не совпало — путь запрещён.
+func decide(p, status string, matchers []matcher) Decision {
+	d := Decision{Path: p, Status: status}
+	if p == "" {
+		d.Allowed = true
+		return d
+	}
+	var last *matcher
+	for i := range matchers {
+		m := &matchers[i]
+		if m.appliesTo(status) && m.re.MatchString(p) {
+			last = m
+		}
+	}
+	if last == nil {
+		d.Rule = "no rule matched (default deny)"
+		return d
+	}
+	d.Allowed = !last.deny
+	d.Rule = last.String()
+	return d
+}
+
+func isAllowed(p, status string, matchers []matcher) bool {
+	return decide(p, status, matchers).Allowed
+}
+
+func statusLead(st string) string {
+	if st == "" {
+		return ""
+	}
+	return st[:1]
+}
+
+func NormalizePath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+	p = strings.ReplaceAll(p, "\\", "/")
+
+	// часто из git diff вылезают префиксы a/ b/
+	for {
+		if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+			p = p[2:]
+			continue
+		}
+		break
+	}
+
+	// если diff делали между baseline/current, может прилипнуть префикс
+	for _, pref := range []string{"./", "baseline/", "current/", "../baseline/"} {
+		if strings.HasPrefix(p, pref) {
+			p = strings.TrimPrefix(p, pref)
+		}
+	}
+
+	p = strings.TrimPrefix(p, "/")
+	p = path.Clean(p)
+	if p == "." {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,209 @@
+package changepolicy
+
+import (
+	"industry_backend_go/internal/config"
+	"reflect"
+	"testing"
+)
+
+func testConfig() config.Config {
+	var cfg config.Config
+	cfg.Diff.AllowList = []string{
+		".git/**",
+		"tasks/task_01/solution.go",
+		"tasks/task_02/solution.go",
+		"docs/",
+		"!docs/private.md",
+	}
+	cfg.Diff.Rules = []config.DiffRule{
+		{Pattern: "tasks/*/solution.go", Action: "deny", Status: "DR"},
+		{Pattern: "tasks/**/*_test.go", Action: "deny"},
+	}
+	return cfg
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		changes    []Change
+		ok         bool
+		unexpected []string
+	}{
+		{
+			name:       "no changes",
+			ok:         true,
+			unexpected: []string{},
+		},
+		{
+			name:       "modified solution",
+			changes:    []Change{{Status: "M", Path: "tasks/task_01/solution.go"}},
+			ok:         true,
+			unexpected: []string{},
+		},
+		{
+			name:       "a/ prefix is a different path",
+			changes:    []Change{{Status: "A", Path: "a/tasks/task_01/solution.go"}},
+			unexpected: []string{"a/tasks/task_01/solution.go"},
+		},
+		{
+			name:       "current/ prefix is a different path",
+			changes:    []Change{{Status: "A", Path: "current/tasks/task_02/solution.go"}},
+			unexpected: []string{"current/tasks/task_02/solution.go"},
+		},
+		{
+			name:       "trailing space is a different path",
+			changes:    []Change{{Status: "A", Path: "tasks/task_01/solution.go "}},
+			unexpected: []string{"tasks/task_01/solution.go "},
+		},
+		{
+			name:       "deleted solution is denied by status rule",
+			changes:    []Change{{Status: "D", Path: "tasks/task_01/solution.go"}},
+			unexpected: []string{"tasks/task_01/solution.go"},
+		},
+		{
+			name:       "renamed solution reports both paths",
+			changes:    []Change{{Status: "R100", From: "tasks/task_01/solution.go", To: "tasks/task_01/sol.go"}},
+			unexpected: []string{"tasks/task_01/sol.go", "tasks/task_01/solution.go"},
+		},
+		{
+			name:       "test file is not in allow list",
+			changes:    []Change{{Status: "M", Path: "tasks/task_01/solution_test.go"}},
+			unexpected: []string{"tasks/task_01/solution_test.go"},
+		},
+		{
+			name: "negated allow list pattern",
+			changes: []Change{
+				{Status: "A", Path: "docs/notes.md"},
+				{Status: "M", Path: "docs/private.md"},
+			},
+			unexpected: []string{"docs/private.md"},
+		},
+		{
+			name:       "unknown status only matches rules without status",
+			changes:    []Change{{Status: "?", Path: "tasks/task_02/solution.go"}},
+			ok:         true,
+			unexpected: []string{},
+		},
+		{
+			name:       "mode change on allowed path",
+			changes:    []Change{{Status: "M", Path: "tasks/task_01/solution.go", OldMode: "100644", NewMode: "100755"}},
+			unexpected: []string{"tasks/task_01/solution.go"},
+		},
+		{
+			name:       "gitlink",
+			changes:    []Change{{Status: "A", Path: "tasks/task_01/solution.go", NewMode: "160000"}},
+			unexpected: []string{"tasks/task_01/solution.go"},
+		},
+		{
+			name:       "symlink to allowed file",
+			changes:    []Change{{Status: "A", Path: "tasks/task_01/solution.go", NewMode: "120000", Target: "../task_02/solution.go"}},
+			ok:         true,
+			unexpected: []string{},
+		},
+		{
+			name:       "symlink to test file",
+			changes:    []Change{{Status: "A", Path: "tasks/task_01/solution.go", NewMode: "120000", Target: "solution_test.go"}},
+			unexpected: []string{"tasks/task_01/solution.go"},
+		},
+		{
+			name:       "symlink out of the repository",
+			changes:    []Change{{Status: "M", Path: "tasks/task_01/solution.go", Target: "../../../etc/passwd"}},
+			unexpected: []string{"tasks/task_01/solution.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rep := Evaluate(testConfig(), tt.changes)
+			if rep.Error != "" {
+				t.Fatalf("Evaluate() error = %s", rep.Error)
+			}
+			if rep.OK != tt.ok {
+				t.Errorf("Evaluate().OK = %v; want %v (decisions %+v)", rep.OK, tt.ok, rep.Decisions)
+			}
+			if !reflect.DeepEqual(rep.Unexpected, tt.unexpected) {
+				t.Errorf("Evaluate().Unexpected = %q; want %q", rep.Unexpected, tt.unexpected)
+			}
+		})
+	}
+}
+
+func TestEvaluateBadConfig(t *testing.T) {
+	t.Parallel()
+
+	var cfg config.Config
+	cfg.Diff.Rules = []config.DiffRule{{Pattern: "x", Action: "maybe"}}
+	rep := Evaluate(cfg, []Change{{Status: "M", Path: "x"}})
+	if rep.OK || rep.Error == "" {
+		t.Fatalf("Evaluate() = ok %v, error %q; want error", rep.OK, rep.Error)
+	}
+}
+
+func TestDecideLastMatchWins(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path, status string
+		allowed      bool
+		rule         string
+	}{
+		{"tasks/task_01/solution.go", "M", true, `allow "tasks/task_01/solution.go" (allow_list[1])`},
+		{"tasks/task_01/solution.go", "D", false, `deny "tasks/*/solution.go" [status DR] (rules[0])`},
+		{"docs/private.md", "M", false, `deny "docs/private.md" (allow_list[4])`},
+		{"README.md", "M", false, "no rule matched (default deny)"},
+		// путь не нормализуется: решение то же, что в Evaluate
+		{"./tasks/task_01/solution.go", "M", false, "no rule matched (default deny)"},
+		{"tasks/task_01/solution.go ", "M", false, "no rule matched (default deny)"},
+	}
+	for _, tt := range tests {
+		d := p.Decide(tt.path, tt.status)
+		if d.Allowed != tt.allowed || d.Rule != tt.rule {
+			t.Errorf("Decide(%q, %q) = %v %q; want %v %q", tt.path, tt.status, d.Allowed, d.Rule, tt.allowed, tt.rule)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		raw        string
+		normalized string
+		allowed    bool
+		verdict    string // вердикт для allow_list[1]
+	}{
+		{"tasks/task_01/solution.go", "", true, "MATCH"},
+		{"tasks/task_01/solution.go ", "tasks/task_01/solution.go", false, "near miss: matches after normalization"},
+		{"a/tasks/task_01/solution.go", "tasks/task_01/solution.go", false, "near miss: matches after normalization"},
+		{"tasks/TASK_01/solution.go", "", false, "near miss: letter case differs"},
+	}
+	for _, tt := range tests {
+		e := p.Explain(tt.raw, "M")
+		if e.Normalized != tt.normalized || e.Decision.Allowed != tt.allowed {
+			t.Errorf("Explain(%q) = normalized %q, allowed %v; want %q, %v", tt.raw, e.Normalized, e.Decision.Allowed, tt.normalized, tt.allowed)
+		}
+		if e.Decision != p.Decide(tt.raw, "M") {
+			t.Errorf("Explain(%q).Decision = %+v; want Decide() result", tt.raw, e.Decision)
+		}
+		verdict := ""
+		for _, m := range e.Matches {
+			if m.Rule == `allow "tasks/task_01/solution.go" (allow_list[1])` {
+				verdict = m.Verdict
+			}
+		}
+		if verdict != tt.verdict {
+			t.Errorf("Explain(%q): allow_list[1] verdict = %q; want %q", tt.raw, verdict, tt.verdict)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package changepolicy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// patchContext — строк контекста вокруг изменений, как у git diff по умолчанию.
+const patchContext = 3
+
+// revertOp — действие, возвращающее путь к baseline: restore — взять файл
+// из baseline, иначе удалить.
+type revertOp struct {
+	path    string
+	restore bool
+	kind    string
+}
+
+// revertOps собирает действия только для недопустимых сторон изменений:
+// у rename, где запрещено лишь новое имя, удаление старого не трогаем.
+func revertOps(rep Report) []revertOp {
+	unexpected := map[string]struct{}{}
+	for _, p := range rep.Unexpected {
+		unexpected[p] = struct{}{}
+	}
+	isUnexpected := func(p string) bool {
+		_, ok := unexpected[p]
+		return ok
+	}
+
+	var out []revertOp
+	seen := map[string]struct{}{}
+	add := func(p string, restore bool, kind string) {
+		if _, ok := seen[p]; ok || p == "" {
+			return
+		}
+		seen[p] = struct{}{}
+		out = append(out, revertOp{path: p, restore: restore, kind: kind})
+	}
+
+	for _, ch := range rep.UnexpectedBySt {
+		switch statusLead(ch.Status) {
+		case "A":
+			add(ch.Path, false, ch.Kind)
+		case "R":
+			if isUnexpected(ch.From) {
+				add(ch.From, true, ch.Kind)
+			}
+			if isUnexpected(ch.To) {
+				add(ch.To, false, ch.Kind)
+			}
+		case "C":
+			add(ch.To, false, ch.Kind)
+		default:
+			// M, D, T и неизвестные статусы — вернуть файл из baseline
+			add(changeKey(ch), true, ch.Kind)
+		}
+	}
+	return out
+}
+
+// RevertScript возвращает sh-скрипт из git checkout / git rm, который откатывает
+// недопустимые изменения. Ref можно переопределить переменной BASELINE_REF.
+func RevertScript(rep Report, baselineRef string) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Reverts changes that are not allowed by the change policy.\n")
+	b.WriteString("# Allowed changes are left untouched. Run from the repository root.\n")
+	b.WriteString("set -e\n")
+	fmt.Fprintf(&b, "BASELINE_REF=\"${BASELINE_REF:-%s}\"\n", baselineRef)
+
+	ops := revertOps(rep)
+	if len(ops) == 0 {
+		b.WriteString("# nothing to revert\n")
+	}
+	for _, op := range ops {
+		if op.restore {
+			fmt.Fprintf(&b, "git checkout \"$BASELINE_REF\" -- %s\n", shellQuote(op.path))
+		} else {
+			fmt.Fprintf(&b, "git rm -f -- %s\n", shellQuote(op.path))
+		}
+	}
+	return []byte(b.String())
+}
+
+// RevertPatch строит unified diff для git apply, который переводит недопустимые
+// пути из current в состояние baseline. Бинарные файлы и gitlink патчем не
+// выразить без git — их пути возвращаются в skipped (для них есть RevertScript).
+func RevertPatch(baseline, current string, rep Report) (patch []byte, skipped []string, err error) {
+	var b strings.Builder
+	for _, op := range revertOps(rep) {
+		cur, err := readEntry(current, op.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var base entry
+		if op.restore {
+			if base, err = readEntry(baseline, op.path); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if cur.mode == modeGitlink || base.mode == modeGitlink || isBinary(cur.data) || isBinary(base.data) {
+			skipped = append(skipped, op.path)
+			continue
+		}
+		writeFilePatch(&b, op.path, cur, base)
+	}
+	return []byte(b.String()), skipped, nil
+}
+
+// entry — содержимое пути в дереве; пустой mode означает, что пути нет.
+type entry struct {
+	mode string
+	data []byte
+}
+
+func readEntry(root, p string) (entry, error) {
+	full := filepath.Join(root, filepath.FromSlash(p))
+	fi, err := os.Lstat(full)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return entry{}, nil
+	case err != nil:
+		return entry{}, err
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(full)
+		return entry{mode: modeSymlink, data: []byte(filepath.ToSlash(target))}, err
+	case fi.IsDir():
+		return entry{mode: modeGitlink}, nil
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return entry{}, err
+	}
+	mode := modeFile
+	if fi.Mode()&0o111 != 0 {
+		mode = modeExec
+	}
+	return entry{mode: mode, data: data}, nil
+}
+
+// writeFilePatch пишет diff одного файла from → to в формате git diff.
+func writeFilePatch(b *strings.Builder, p string, from, to entry) {
+	if from.mode == "" && to.mode == "" {
+		return
+	}
+	if from.mode == to.mode && string(from.data) == string(to.data) {
+		return
+	}
+
+	if from.mode != "" && to.mode != "" && modeType(from.mode) != modeType(to.mode) {
+		// смена типа в git — это удаление и создание; разбиваем на два diff
+		writeFilePatch(b, p, from, entry{})
+		writeFilePatch(b, p, entry{}, to)
+		return
+	}
+
+	a, z := patchName("a/", p), patchName("b/", p)
+	fmt.Fprintf(b, "diff --git %s %s\n", a, z)
+	switch {
+	case from.mode == "":
+		fmt.Fprintf(b, "new file mode %s\n", to.mode)
+		a = "/dev/null"
+	case to.mode == "":
+		fmt.Fprintf(b, "deleted file mode %s\n", from.mode)
+		z = "/dev/null"
+	case from.mode != to.mode:
+		fmt.Fprintf(b, "old mode %s\nnew mode %s\n", from.mode, to.mode)
+	}
+
+	if string(from.data) == string(to.data) {
+		// только режим или пустой файл — без ханков
+		return
+	}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", a, z)
+	writeHunks(b, diffLines(textLines(from.data), textLines(to.data)))
+}
+
+// writeHunks группирует операции в ханки с patchContext строками контекста.
+func writeHunks(b *strings.Builder, ops []diffOp) {
+	// oldAt/newAt[i] — номер строки (с 0) перед операцией i
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if op.kind != '+' {
+			oldAt[i+1]++
+		}
+		if op.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(0, i-patchContext)
+		end := i
+		// расширяем ханк, пока следующий разрыв между изменениями не длиннее 2*context
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*patchContext {
+				break
+			}
+		}
+		end = min(len(ops), end+patchContext)
+
+		oldStart, oldLen := oldAt[start], oldAt[end]-oldAt[start]
+		newStart, newLen := newAt[start], newAt[end]-newAt[start]
+		fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+}
+
+// hunkRange форматирует диапазон как git: "l,n", "l" при n == 1 и "l-1,0" для пустого.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// patchName — имя файла в заголовке патча; необычные пути git берёт в C-кавычки.
+func patchName(prefix, p string) string {
+	name := prefix + p
+	needQuote := false
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			needQuote = true
+			break
+		}
+	}
+	if !needQuote {
+		return name
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, `\%03o`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
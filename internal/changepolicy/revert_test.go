@@ -0,0 +1,158 @@
+package changepolicy
+
+import (
+	"industry_backend_go/internal/config"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// revertFixture — baseline и current, где допустимо только изменение
+// tasks/task_01/solution.go; после отката остальное должно совпасть с baseline.
+var revertFixture = struct {
+	baseline, current, want map[string]string
+}{
+	baseline: map[string]string{
+		"tasks/task_01/solution.go":      "package task01\n",
+		"tasks/task_01/solution_test.go": "package task01\n\nfunc TestA() {}", // без \n в конце
+		"tasks/task_02/data.txt":         "a\nb\nc\nd\ne\nf\ng\nh\n",
+		"docs/removed.md":                "gone\n",
+		"docs/empty.txt":                 "",
+		"r/old name.txt":                 strings.Repeat("same line\n", 10),
+	},
+	current: map[string]string{
+		"tasks/task_01/solution.go":      "package task01\n\nfunc Solve() {}\n",
+		"tasks/task_01/solution_test.go": "package task01\n\nfunc TestA() {}\nfunc TestB() {}\n",
+		"tasks/task_02/data.txt":         "a\nB\nc\nd\ne\nf\ng\nH",
+		"tasks/task_02/added.go":         "package task02\n",
+		"tasks/task_02/empty.go":         "",
+		"r/new name.txt":                 strings.Repeat("same line\n", 10),
+	},
+	want: map[string]string{
+		"tasks/task_01/solution.go":      "package task01\n\nfunc Solve() {}\n",
+		"tasks/task_01/solution_test.go": "package task01\n\nfunc TestA() {}",
+		"tasks/task_02/data.txt":         "a\nb\nc\nd\ne\nf\ng\nh\n",
+		"docs/removed.md":                "gone\n",
+		"docs/empty.txt":                 "",
+		"r/old name.txt":                 strings.Repeat("same line\n", 10),
+	},
+}
+
+func revertReport(t *testing.T, baseline, current string) Report {
+	t.Helper()
+	changes, err := CompareDirs(baseline, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg config.Config
+	cfg.Diff.AllowList = []string{"tasks/task_01/solution.go"}
+	rep := Evaluate(cfg, changes)
+	if rep.OK {
+		t.Fatalf("Evaluate() = ok; want unexpected changes")
+	}
+	return rep
+}
+
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		out[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRevertPatch(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	baseline := writeTree(t, revertFixture.baseline)
+	current := writeTree(t, revertFixture.current)
+	rep := revertReport(t, baseline, current)
+
+	patch, skipped, err := RevertPatch(baseline, current, rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("RevertPatch() skipped %q; want none", skipped)
+	}
+
+	// git apply вне репозитория работает как patch -p1
+	cmd := exec.Command("git", "apply", "--whitespace=nowarn", "-")
+	cmd.Dir = current
+	cmd.Stdin = strings.NewReader(string(patch))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v\n%s\npatch:\n%s", err, out, patch)
+	}
+	if got := readTree(t, current); !reflect.DeepEqual(got, revertFixture.want) {
+		t.Fatalf("tree after git apply =\n%q\nwant\n%q\npatch:\n%s", got, revertFixture.want, patch)
+	}
+}
+
+func TestRevertScript(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	baseline := writeTree(t, revertFixture.baseline)
+	current := writeTree(t, revertFixture.current)
+	rep := revertReport(t, baseline, current)
+
+	// репозиторий студента: коммит baseline, поверх — коммит с current
+	repo := writeTree(t, revertFixture.baseline)
+	git(t, repo, "init", "-q", "-b", "main")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "baseline")
+	base := git(t, repo, "rev-parse", "HEAD")
+	git(t, repo, "rm", "-q", "-r", ".")
+	for name, body := range revertFixture.current {
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "solution")
+
+	script := filepath.Join(t.TempDir(), "revert.sh")
+	if err := os.WriteFile(script, RevertScript(rep, base), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", script)
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("revert script: %v\n%s", err, out)
+	}
+	if got := readTree(t, repo); !reflect.DeepEqual(got, revertFixture.want) {
+		t.Fatalf("tree after revert script =\n%q\nwant\n%q", got, revertFixture.want)
+	}
+}
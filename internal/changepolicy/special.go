@@ -0,0 +1,125 @@
+package changepolicy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// режимы git-объектов (как в git diff --raw)
+const (
+	modeFile    = "100644"
+	modeExec    = "100755"
+	modeSymlink = "120000"
+	modeGitlink = "160000"
+)
+
+// виды особых изменений (Change.Kind)
+const (
+	kindType    = "type"    // файл ↔ симлинк ↔ gitlink
+	kindGitlink = "gitlink" // подмодуль
+	kindSymlink = "symlink"
+	kindMode    = "mode" // смена executable-бита
+)
+
+func modeType(mode string) string {
+	switch mode {
+	case modeFile, modeExec:
+		return "file"
+	case modeSymlink:
+		return "symlink"
+	case modeGitlink:
+		return "gitlink"
+	}
+	return ""
+}
+
+// classifyChange определяет вид особого изменения по статусу и режимам.
+func classifyChange(ch Change) string {
+	oldType, newType := modeType(ch.OldMode), modeType(ch.NewMode)
+	switch {
+	case oldType == "gitlink" || newType == "gitlink":
+		return kindGitlink
+	case statusLead(ch.Status) == "T" || (oldType != "" && newType != "" && oldType != newType):
+		return kindType
+	case newType == "symlink" || (statusLead(ch.Status) != "D" && ch.Target != ""):
+		return kindSymlink
+	case oldType == "file" && newType == "file" && ch.OldMode != ch.NewMode:
+		return kindMode
+	}
+	return ""
+}
+
+// specialReason возвращает причину отклонить изменение, даже если сам путь разрешён.
+// Симлинк допустим, только если его цель остаётся внутри разрешённого набора.
+func specialReason(ch Change, matchers []matcher) string {
+	switch ch.Kind {
+	case kindGitlink:
+		return "submodule (gitlink) entries are not allowed"
+	case kindMode:
+		return fmt.Sprintf("file mode changed: %s -> %s", ch.OldMode, ch.NewMode)
+	case kindType:
+		if ch.NewMode == modeSymlink {
+			if reason := symlinkReason(ch, matchers); reason != "" {
+				return "file type changed to symlink: " + reason
+			}
+			return ""
+		}
+		if ch.OldMode == "" || ch.NewMode == "" {
+			// по --name-status не понять, во что превратился файл
+			return "file type changed"
+		}
+		return ""
+	case kindSymlink:
+		if statusLead(ch.Status) == "D" {
+			return ""
+		}
+		return symlinkReason(ch, matchers)
+	}
+	return ""
+}
+
+func symlinkReason(ch Change, matchers []matcher) string {
+	if ch.Target == "" {
+		return "symlink target is unknown"
+	}
+	if path.IsAbs(ch.Target) {
+		return fmt.Sprintf("symlink points outside the repository: %s", ch.Target)
+	}
+	target := path.Join(path.Dir(changeKey(ch)), ch.Target)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return fmt.Sprintf("symlink points outside the repository: %s", ch.Target)
+	}
+	if !isAllowed(target, "M", matchers) {
+		return fmt.Sprintf("symlink target %s is not allowed", target)
+	}
+	return ""
+}
+
+// FillSymlinkTargets дополняет изменения из git-вывода: ищет симлинки в
+// текущем дереве root и читает их цели.
+func FillSymlinkTargets(root string, changes []Change) {
+	for i := range changes {
+		ch := &changes[i]
+		if statusLead(ch.Status) == "D" {
+			continue
+		}
+		p := changeKey(*ch)
+		if p == "" {
+			continue
+		}
+		full := filepath.Join(root, filepath.FromSlash(p))
+		fi, err := os.Lstat(full)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if target, err := os.Readlink(full); err == nil {
+			ch.Target = filepath.ToSlash(target)
+			if ch.NewMode == "" {
+				ch.NewMode = modeSymlink
+			}
+		}
+	}
+}
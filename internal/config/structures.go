@@ -5,14 +5,81 @@ type Config struct {
 	Stream  string `json:"stream"`
 
 	Tests struct {
-		IgnorePackages []string `json:"ignore_packages"`
+		IgnorePackages []string         `json:"ignore_packages"` // путь, glob или префикс "mod/cmd/..."
+		Benchmarks     []BenchmarkLimit `json:"benchmarks"`
 	} `json:"tests"`
 
 	Diff struct {
 		Original struct {
-			Repo   string `json:"repo"`
-			Branch string `json:"branch"`
+			Repo string `json:"repo"`
+			Ref  string `json:"ref"`
 		} `json:"original"`
-		AllowList []string `json:"allow_list"`
+		AllowList []string     `json:"allow_list"`
+		Rules     []DiffRule   `json:"rules"`
+		Imports   ImportPolicy `json:"imports"`
+		Budgets   []DiffBudget `json:"budgets"`
 	} `json:"diff"`
+
+	Scoring Scoring `json:"scoring"`
+}
+
+// BenchmarkLimit — пороги для бенчмарков (медиана по -count). Нулевой порог
+// не проверяется. Абсолютные ns/op зависят от машины, поэтому для сложности
+// алгоритма удобнее relative_to: ns/op Name не больше max_ratio × ns/op RelativeTo.
+type BenchmarkLimit struct {
+	Package        string  `json:"package"` // import path, шаблон path.Match
+	Name           string  `json:"name"`    // regexp по имени бенчмарка без суффикса -GOMAXPROCS
+	MaxNsPerOp     float64 `json:"max_ns_per_op,omitempty"`
+	MaxBytesPerOp  float64 `json:"max_bytes_per_op,omitempty"`
+	MaxAllocsPerOp float64 `json:"max_allocs_per_op,omitempty"`
+	RelativeTo     string  `json:"relative_to,omitempty"` // имя другого бенчмарка того же пакета
+	MaxRatio       float64 `json:"max_ratio,omitempty"`
+}
+
+// Scoring — баллы за задачи. Для пакета берётся последняя подошедшая запись.
+type Scoring struct {
+	Tasks []TaskScore `json:"tasks"`
+}
+
+// TaskScore: Points — за пакет целиком (status pass), Tests — дополнительно
+// за тесты, чьё имя подходит под регулярное выражение.
+type TaskScore struct {
+	Package string      `json:"package"`           // import path, шаблон path.Match
+	Points  float64     `json:"points,omitempty"`  // за пакет целиком
+	Partial bool        `json:"partial,omitempty"` // доля прошедших тестов вместо всё или ничего; после panic/timeout — 0
+	Tests   []TestScore `json:"tests,omitempty"`
+}
+
+type TestScore struct {
+	Pattern string  `json:"pattern"` // regexp по имени теста (включая подтесты "TestX/sub")
+	Points  float64 `json:"points"`
+}
+
+// DiffRule — правило политики изменений. Правила применяются после allow_list,
+// побеждает последнее совпавшее (как в .gitignore).
+type DiffRule struct {
+	Pattern string `json:"pattern"`          // glob; "!pattern" инвертирует action
+	Action  string `json:"action"`           // allow|deny
+	Status  string `json:"status,omitempty"` // буквы статусов git (A/M/D/R/C/T), пусто = любой
+}
+
+// DiffBudget ограничивает размер изменений файлов, подходящих под Pattern.
+// Нулевой лимит = без ограничения.
+type DiffBudget struct {
+	Pattern    string `json:"pattern"`
+	MaxAdded   int    `json:"max_added,omitempty"`
+	MaxRemoved int    `json:"max_removed,omitempty"`
+	MaxChanged int    `json:"max_changed,omitempty"` // added + removed
+	NoBinary   bool   `json:"no_binary,omitempty"`
+}
+
+// ImportPolicy — ограничения на импорты в изменённых .go файлах.
+type ImportPolicy struct {
+	StdlibOnly *bool    `json:"stdlib_only,omitempty"` // по умолчанию true
+	Deny       []string `json:"deny,omitempty"`        // пакеты ("os/exec") или поддеревья ("net/...")
+	Allow      []string `json:"allow,omitempty"`       // исключения из stdlib_only
+}
+
+func (p ImportPolicy) IsStdlibOnly() bool {
+	return p.StdlibOnly == nil || *p.StdlibOnly
 }
@@ -0,0 +1,28 @@
+// Package gomod читает go.mod без зависимости от golang.org/x/mod.
+package gomod
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ModulePath возвращает путь модуля из директивы module файла goMod;
+// пусто, если файла нет или директива не найдена.
+func ModulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`+"`")
+		}
+	}
+	return ""
+}
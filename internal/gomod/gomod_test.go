@@ -0,0 +1,34 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModulePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, content, want string
+	}{
+		{"plain", "module example.com/m\n\ngo 1.22\n", "example.com/m"},
+		{"quoted", "module \"example.com/q\"\n", "example.com/q"},
+		{"raw string", "module `example.com/r`\n", "example.com/r"},
+		{"comment", "// generated\nmodule m // the module\n", "m"},
+		{"no module", "go 1.22\n", ""},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name+".mod")
+		if err := os.WriteFile(p, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := ModulePath(p); got != tt.want {
+			t.Errorf("%s: ModulePath() = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := ModulePath(filepath.Join(dir, "missing.mod")); got != "" {
+		t.Errorf("ModulePath(missing) = %q; want empty", got)
+	}
+}
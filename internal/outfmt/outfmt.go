@@ -0,0 +1,74 @@
+// Package outfmt — общий для change_check и testreport разбор -format
+// и путей файлов -out.
+package outfmt
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Format — имя формата в -format и расширение его файла.
+type Format struct {
+	Name string
+	Ext  string
+}
+
+// Set — поддерживаемые форматы в том порядке, в каком их перечисляет ошибка.
+type Set []Format
+
+// Parse разбирает список форматов через запятую: регистр и пробелы не
+// важны, повторы отбрасываются, порядок сохраняется.
+func (s Set) Parse(list string) ([]string, error) {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, f := range strings.Split(list, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if _, ok := s.ext(f); !ok {
+			return nil, fmt.Errorf("unknown format %q (want %s)", f, s.names())
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no output format given")
+	}
+	return out, nil
+}
+
+// OutputPath: при одном формате пишем ровно в out, при нескольких —
+// меняем расширение out на расширение формата.
+func (s Set) OutputPath(out, format string, multi bool) string {
+	if !multi {
+		return out
+	}
+	ext, _ := s.ext(format)
+	return strings.TrimSuffix(out, path.Ext(out)) + ext
+}
+
+func (s Set) ext(name string) (string, bool) {
+	for _, f := range s {
+		if f.Name == name {
+			return f.Ext, true
+		}
+	}
+	return "", false
+}
+
+// names — "json, junit or tap".
+func (s Set) names() string {
+	names := make([]string, len(s))
+	for i, f := range s {
+		names[i] = f.Name
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
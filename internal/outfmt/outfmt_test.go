@@ -0,0 +1,57 @@
+package outfmt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testSet = Set{{"json", ".json"}, {"sarif", ".sarif"}, {"junit", ".xml"}}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr string
+	}{
+		{"json", []string{"json"}, ""},
+		{" JSON , junit,json,,sarif ", []string{"json", "junit", "sarif"}, ""},
+		{"", nil, "no output format given"},
+		{" , ", nil, "no output format given"},
+		{"json,tap", nil, `unknown format "tap" (want json, sarif or junit)`},
+	}
+	for _, tt := range tests {
+		got, err := testSet.Parse(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v; want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		out, format string
+		multi       bool
+		want        string
+	}{
+		{"result.json", "sarif", false, "result.json"},
+		{"result.json", "sarif", true, "result.sarif"},
+		{"out/result.json", "junit", true, "out/result.xml"},
+		{"result", "json", true, "result.json"},
+		{"out.d/result", "junit", true, "out.d/result.xml"},
+	}
+	for _, tt := range tests {
+		if got := testSet.OutputPath(tt.out, tt.format, tt.multi); got != tt.want {
+			t.Errorf("OutputPath(%q, %q, %v) = %q; want %q", tt.out, tt.format, tt.multi, got, tt.want)
+		}
+	}
+}